@@ -10,10 +10,15 @@ import (
 	"time"
 
 	"github.com/ish-xyz/go-kubetest/pkg/assert"
+	"github.com/ish-xyz/go-kubetest/pkg/chaos"
+	"github.com/ish-xyz/go-kubetest/pkg/client/clientset/versioned"
 	"github.com/ish-xyz/go-kubetest/pkg/controller"
+	"github.com/ish-xyz/go-kubetest/pkg/diagnostics"
 	"github.com/ish-xyz/go-kubetest/pkg/loader"
 	"github.com/ish-xyz/go-kubetest/pkg/metrics"
+	"github.com/ish-xyz/go-kubetest/pkg/plugin"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/ish-xyz/go-kubetest/pkg/webhook"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/dynamic"
@@ -28,6 +33,24 @@ var (
 	kubeconfig     string
 	metricsAddress string
 	cpuProfile     string
+	failSeverity   string
+	pluginDir      string
+	chaosNS        []string
+	chaosMax       int
+	chaosNodes     bool
+	teardownPolicy string
+	keepOnFailure  bool
+	retentionTTL   time.Duration
+	retryBackoff   time.Duration
+	teardownBudget time.Duration
+	diagDir        string
+	diagMaxSize    int
+	diagTimeout    time.Duration
+	concurrency    int
+	webhookAddress string
+	webhookCert    string
+	webhookKey     string
+	unhealthyAfter time.Duration
 	interval       int
 	debug          bool
 	once           bool
@@ -52,9 +75,27 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&metricsAddress, "metrics-address", "m", "0.0.0.0:9000", "Run the controller in debug mode")
 	rootCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "Kubernetes config file path")
 	rootCmd.PersistentFlags().StringVarP(&cpuProfile, "cpu-profile", "p", "", "Path to save the cpu-profile file")
+	rootCmd.PersistentFlags().StringVar(&pluginDir, "plugin-dir", "", "Directory with the kubetest-<type> executables handling custom assertion types")
+	rootCmd.PersistentFlags().StringSliceVar(&chaosNS, "chaos-namespaces", []string{}, "Namespaces where chaos actions can delete or evict pods and scale deployments")
+	rootCmd.PersistentFlags().IntVar(&chaosMax, "chaos-max-affected", 1, "Max number of objects affected by a single chaos action")
+	rootCmd.PersistentFlags().BoolVar(&chaosNodes, "chaos-allow-nodes", false, "Allow the chaos actions on nodes (cordonNode, drainNode)")
+	rootCmd.PersistentFlags().StringVar(&teardownPolicy, "teardown-policy", loader.TeardownAlways, "Default teardown policy of the tests (always, onSuccess, never)")
+	rootCmd.PersistentFlags().BoolVar(&keepOnFailure, "keep-on-failure", false, "Keep the resources of failed tests for debugging, whatever their teardown policy")
+	rootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", 10*time.Second, "Wait before the first retry of a failed test (see retries), doubled after each retry")
+	rootCmd.PersistentFlags().DurationVar(&teardownBudget, "teardown-timeout", 5*time.Minute, "Time budget of the teardown of a test, on top of the test timeout, 0 disables it")
+	rootCmd.PersistentFlags().DurationVar(&retentionTTL, "retention-ttl", time.Hour, "How long the controller keeps the resources retained by the teardown policy, 0 keeps them until the test runs again")
+	rootCmd.PersistentFlags().StringVar(&diagDir, "diagnostics-dir", "", "Directory where the diagnostics of failed tests are written (--once only), otherwise a summary is attached to the TestResult")
+	rootCmd.PersistentFlags().DurationVar(&diagTimeout, "diagnostics-timeout", 2*time.Minute, "Time budget of the diagnostics collection of a failed test, before the teardown budget starts, 0 disables it")
+	rootCmd.PersistentFlags().IntVar(&diagMaxSize, "diagnostics-max-size", 16*1024, "Max size in bytes of the diagnostics summary attached to a TestResult")
+	rootCmd.PersistentFlags().StringVar(&webhookAddress, "webhook-address", "0.0.0.0:9443", "Address of the CRD conversion webhook")
+	rootCmd.PersistentFlags().StringVar(&webhookCert, "webhook-cert", "", "TLS certificate of the CRD conversion webhook, the webhook runs only with a certificate and a key")
+	rootCmd.PersistentFlags().StringVar(&webhookKey, "webhook-key", "", "TLS key of the CRD conversion webhook")
+	rootCmd.PersistentFlags().DurationVar(&unhealthyAfter, "unhealthy-after", 10*time.Minute, "How long the tests can keep failing to load before /healthz fails, 0 disables it")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 1, "Max number of tests running in parallel, dependencies (dependsOn) are always respected")
 	rootCmd.PersistentFlags().IntVarP(&interval, "interval", "i", 1200, "The interval between one test execution and the next one")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Run the controller in debug mode")
 	rootCmd.PersistentFlags().BoolVarP(&once, "once", "o", false, "Run controller only once")
+	rootCmd.PersistentFlags().StringVar(&failSeverity, "fail-severity", loader.SeverityCritical, "Lowest severity of a failed assertion that makes --once exit with a non-zero code (critical, warning, info)")
 	rootCmd.PersistentFlags().StringToStringVarP(
 		&selectors,
 		"select",
@@ -71,6 +112,20 @@ func handleErr(err error) {
 	}
 }
 
+// Return the current context of the kubeconfig file, empty when running in-cluster
+func getCurrentContext(kubeconfig string) string {
+
+	if kubeconfig == "" {
+		return ""
+	}
+	config, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		logrus.Debugln(err)
+		return ""
+	}
+	return config.CurrentContext
+}
+
 func exec(cmd *cobra.Command, args []string) {
 
 	var restConfig *rest.Config
@@ -88,6 +143,10 @@ func exec(cmd *cobra.Command, args []string) {
 	if debug {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
+	if loader.SeverityRank(failSeverity) < 0 {
+		handleErr(fmt.Errorf("unknown severity '%s'", failSeverity))
+	}
+	handleErr(loader.ValidateTeardownPolicy(teardownPolicy))
 	if kubeconfig == "" {
 		restConfig, err = rest.InClusterConfig()
 	} else {
@@ -106,13 +165,42 @@ func exec(cmd *cobra.Command, args []string) {
 	port, err := strconv.Atoi(metricsAddressList[1])
 	handleErr(err)
 
+	kc, err := versioned.NewForConfig(restConfig)
+	handleErr(err)
+
 	metricsCtrl := metrics.NewMetricsController(dynclient, address, port)
 
 	// initiate objects
 	prv := provisioner.NewProvisioner(restConfig, client, dynclient)
 	asrt := assert.NewAssert(prv)
+	if pluginDir != "" {
+		asrt.Plugins, err = plugin.NewManager(pluginDir, kubeconfig, getCurrentContext(kubeconfig))
+		handleErr(err)
+	}
 	ldr = loader.NewKubernetesLoader(prv)
 	controllerInstance := controller.NewController(ldr, prv, metricsCtrl, asrt)
+	controllerInstance.Client = kc
+	controllerInstance.FailSeverity = failSeverity
+	controllerInstance.Concurrency = concurrency
+	controllerInstance.Chaos = chaos.NewRunner(prv, chaosNS, chaosMax, chaosNodes)
+	controllerInstance.TeardownPolicy = teardownPolicy
+	controllerInstance.KeepOnFailure = keepOnFailure
+	controllerInstance.RetentionTTL = retentionTTL
+	controllerInstance.RetryBackoff = retryBackoff
+	controllerInstance.TeardownTimeout = teardownBudget
+	controllerInstance.UnhealthyAfter = unhealthyAfter
+	if !once {
+		diagDir = ""
+	}
+	controllerInstance.Diagnostics = diagnostics.NewCollector(prv, diagDir, diagMaxSize, 0)
+	controllerInstance.DiagnosticsTimeout = diagTimeout
+
+	// Start the conversion webhook, the CRD has to be patched to use it (see docs/versions.md)
+	if webhookCert != "" && webhookKey != "" {
+		go func() {
+			handleErr(webhook.NewServer(webhookAddress, webhookCert, webhookKey).Run())
+		}()
+	}
 
 	// Prepare selectors
 	sl := make(map[string]interface{}, len(selectors))
@@ -121,5 +209,6 @@ func exec(cmd *cobra.Command, args []string) {
 	}
 
 	// Start controller
-	controllerInstance.Run(context.TODO(), namespace, sl, time.Duration(interval)*time.Second, once)
+	err = controllerInstance.Run(context.TODO(), namespace, sl, time.Duration(interval)*time.Second, once)
+	handleErr(err)
 }
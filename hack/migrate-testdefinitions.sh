@@ -0,0 +1,60 @@
+#!/usr/bin/env bash
+#
+# Move the TestDefinitions from the tests.go-kubetest.io CRD to testdefinitions.go-kubetest.io.
+# The name of a CRD can't change and two CRDs can't serve the same kind, so the objects are
+# exported, the old CRD is deleted (with its objects) and the objects are created again.
+# Nothing is deleted until the export has been checked and the new CRD has been accepted.
+#
+# Usage: hack/migrate-testdefinitions.sh [backup file]
+#
+# Requires kubectl and jq. Stop the controller before running it.
+#
+# Restore: the backup holds every TestDefinition, it can be created again with
+#   kubectl create -f <backup file>
+# once the new CRD (crds/go-kubetest.io_testdefinitions.yaml) or the old one is established.
+
+set -euo pipefail
+
+OLD_CRD="tests.go-kubetest.io"
+NEW_CRD="testdefinitions.go-kubetest.io"
+CRD_FILE="$(dirname "$0")/../crds/go-kubetest.io_testdefinitions.yaml"
+BACKUP="${1:-testdefinitions-backup.json}"
+
+if ! kubectl get crd "${OLD_CRD}" > /dev/null 2>&1; then
+    echo "CRD ${OLD_CRD} not found, nothing to migrate"
+    exit 0
+fi
+
+echo "Exporting the TestDefinitions to ${BACKUP}"
+kubectl get "tests.v1.go-kubetest.io" --all-namespaces -o json \
+    | jq 'del(.items[].status,
+              .items[].metadata.uid,
+              .items[].metadata.resourceVersion,
+              .items[].metadata.generation,
+              .items[].metadata.creationTimestamp,
+              .items[].metadata.managedFields,
+              .items[].metadata.selfLink)' > "${BACKUP}"
+
+# The export must be readable and complete before anything is deleted
+EXPORTED="$(jq '.items | length' "${BACKUP}")"
+LIVE="$(kubectl get "tests.v1.go-kubetest.io" --all-namespaces -o name | wc -l | tr -d ' ')"
+if [ "${EXPORTED}" != "${LIVE}" ]; then
+    echo "${EXPORTED} TestDefinitions exported but ${LIVE} found, nothing has been deleted" >&2
+    exit 1
+fi
+echo "${EXPORTED} TestDefinitions exported"
+
+# The new CRD can't be established while the old one serves the same kind: its names are
+# only accepted once the old CRD is deleted, a KindConflict is expected until then
+echo "Creating CRD ${NEW_CRD}"
+kubectl apply -f "${CRD_FILE}"
+kubectl wait --for condition=NamesAccepted=False --timeout=60s "crd/${NEW_CRD}"
+
+trap 'echo "Migration failed, restore the TestDefinitions with: kubectl create -f ${BACKUP}" >&2' ERR
+
+echo "Deleting CRD ${OLD_CRD}"
+kubectl delete crd "${OLD_CRD}" --wait=true
+kubectl wait --for condition=established --timeout=60s "crd/${NEW_CRD}"
+
+echo "Restoring the TestDefinitions"
+kubectl create -f "${BACKUP}"
@@ -0,0 +1,11 @@
+//go:build tools
+
+// Package tools pins the version of the client generators run by hack/update-codegen.sh,
+// it matches the version of client-go
+package tools
+
+import (
+	_ "k8s.io/code-generator/cmd/client-gen"
+	_ "k8s.io/code-generator/cmd/informer-gen"
+	_ "k8s.io/code-generator/cmd/lister-gen"
+)
@@ -0,0 +1,8 @@
+//go:build tools
+
+// Package tools pins the version of controller-gen run by hack/update-codegen.sh
+package tools
+
+import (
+	_ "sigs.k8s.io/controller-tools/cmd/controller-gen"
+)
@@ -0,0 +1,63 @@
+#!/usr/bin/env bash
+#
+# Generate the deepcopy functions and the CRDs in crds/ with controller-gen, and the clientset,
+# listers and informers in pkg/client with client-gen, lister-gen and informer-gen.
+# The versions of the generators are pinned by the modules in hack/tools.
+#
+# Usage: hack/update-codegen.sh (or go generate ./...)
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+MODULE="github.com/ish-xyz/go-kubetest"
+APIS="${MODULE}/pkg/apis"
+CLIENT="${MODULE}/pkg/client"
+
+TMP="$(mktemp -d)"
+trap 'rm -rf "${TMP}"' EXIT
+
+echo "Building the generators"
+(cd "${ROOT}/hack/tools/controller-gen" && GOBIN="${TMP}/bin" go install sigs.k8s.io/controller-tools/cmd/controller-gen)
+(cd "${ROOT}/hack/tools/code-generator" && GOBIN="${TMP}/bin" go install \
+    k8s.io/code-generator/cmd/client-gen \
+    k8s.io/code-generator/cmd/lister-gen \
+    k8s.io/code-generator/cmd/informer-gen)
+
+cd "${ROOT}"
+export GOFLAGS=-mod=readonly
+
+# Flakiness is a float, existing TestResults store it as a number
+echo "Generating the deepcopy functions and the CRDs"
+"${TMP}/bin/controller-gen" \
+    object \
+    crd:crdVersions=v1,allowDangerousTypes=true \
+    paths=./pkg/apis/... \
+    output:crd:dir=crds
+
+# The client generators write in a GOPATH-like tree, only the generated packages are copied
+echo "Generating the clientset, listers and informers"
+touch "${TMP}/header.txt"
+"${TMP}/bin/client-gen" \
+    --go-header-file "${TMP}/header.txt" \
+    --clientset-name versioned \
+    --input-base "${APIS}" \
+    --input gokubetest/v1 \
+    --output-package "${CLIENT}/clientset" \
+    --output-base "${TMP}/src"
+"${TMP}/bin/lister-gen" \
+    --go-header-file "${TMP}/header.txt" \
+    --input-dirs "${APIS}/gokubetest/v1" \
+    --output-package "${CLIENT}/listers" \
+    --output-base "${TMP}/src"
+"${TMP}/bin/informer-gen" \
+    --go-header-file "${TMP}/header.txt" \
+    --input-dirs "${APIS}/gokubetest/v1" \
+    --versioned-clientset-package "${CLIENT}/clientset/versioned" \
+    --listers-package "${CLIENT}/listers" \
+    --output-package "${CLIENT}/informers" \
+    --output-base "${TMP}/src"
+
+for dir in clientset listers informers; do
+    rm -rf "pkg/client/${dir}"
+    cp -r "${TMP}/src/${CLIENT}/${dir}" "pkg/client/${dir}"
+done
@@ -0,0 +1,21 @@
+#!/usr/bin/env bash
+#
+# Check that the generated files are up to date with the API types.
+# The files are generated in place, run it on a clean tree (e.g. in CI).
+#
+# Usage: hack/verify-codegen.sh
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+GENERATED=(crds pkg/apis pkg/client)
+
+"${ROOT}/hack/update-codegen.sh"
+
+cd "${ROOT}"
+if [ -n "$(git status --porcelain -- "${GENERATED[@]}")" ]; then
+    echo "The generated files are out of date, run hack/update-codegen.sh" >&2
+    git status --short -- "${GENERATED[@]}" >&2
+    exit 1
+fi
+echo "The generated files are up to date"
@@ -0,0 +1,12 @@
+// +kubebuilder:object:generate=true
+// +groupName=go-kubetest.io
+// +groupGoName=Kubetest
+
+// Package v1 contains the types of the go-kubetest.io/v1 API: TestDefinition,
+// TestResource, TestFixture and TestResult.
+//
+// The deepcopy functions, the CRDs in crds/ and the clientset, listers and informers
+// in pkg/client are generated from these types by hack/update-codegen.sh.
+//
+//go:generate bash ../../../../hack/update-codegen.sh
+package v1
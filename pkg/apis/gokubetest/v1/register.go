@@ -0,0 +1,45 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const GroupName = "go-kubetest.io"
+
+// Resource names (plurals) of the kinds
+const (
+	TestDefinitionResource = "testdefinitions"
+	TestResourceResource   = "testresources"
+	TestFixtureResource    = "testfixtures"
+	TestResultResource     = "testresults"
+)
+
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1"}
+
+var (
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	AddToScheme   = SchemeBuilder.AddToScheme
+)
+
+// Resource returns the GroupResource of a resource of this group
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&TestDefinition{},
+		&TestDefinitionList{},
+		&TestResource{},
+		&TestResourceList{},
+		&TestFixture{},
+		&TestFixtureList{},
+		&TestResult{},
+		&TestResultList{},
+	)
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
@@ -0,0 +1,413 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestDefinition describes a test: the resources to create, the assertions to run and the steps
+//
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=testdefinitions,singular=testdefinition,shortName=tdef,scope=Namespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name=Result,type=string,description=The result of the last run,JSONPath=.status.lastResult
+// +kubebuilder:printcolumn:name="Last Run",type=date,description=When the test last ran,JSONPath=.status.lastRunTime
+// +kubebuilder:printcolumn:name="Next Run",type=date,description=When the test runs next,JSONPath=.status.nextRunTime
+// +kubebuilder:printcolumn:name=Age,type=date,JSONPath=.metadata.creationTimestamp
+// +kubebuilder:storageversion
+type TestDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TestDefinitionSpec `json:"spec"`
+	// +optional
+	Status TestDefinitionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type TestDefinitionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TestDefinition `json:"items"`
+}
+
+type TestDefinitionSpec struct {
+	// Names of the TestResources created by setup
+	Resources []string `json:"resources"`
+
+	Setup    Phase `json:"setup"`
+	Teardown Phase `json:"teardown"`
+
+	// Names of the tests that have to pass before this one runs
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Names of the TestFixtures set up before this test
+	// +optional
+	Fixtures []string `json:"fixtures,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^(always|onSuccess|never)$`
+	// +optional
+	TeardownPolicy string `json:"teardownPolicy,omitempty"`
+
+	// How many times a failed test runs again
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// Max duration of setup, assertions and steps
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	Assert []Assertion `json:"assert"`
+
+	// +optional
+	Steps []Step `json:"steps,omitempty"`
+}
+
+// Status of the last run of a test, set by the controller
+type TestDefinitionStatus struct {
+	// One of: Passed, Failed, Skipped
+	// +optional
+	LastResult string `json:"lastResult,omitempty"`
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	LastRunTime string `json:"lastRunTime,omitempty"`
+	// Empty when the controller runs once
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	NextRunTime string `json:"nextRunTime,omitempty"`
+	// Errors found while loading the test, e.g. missing TestResources. The test failed without running
+	// +optional
+	LoadErrors []string `json:"loadErrors,omitempty"`
+}
+
+// Resources waited for at the end of setup or teardown
+type Phase struct {
+	// +optional
+	WaitFor []WaitFor `json:"waitFor,omitempty"`
+}
+
+type WaitFor struct {
+	// apiVersion:Kind[:namespace]:name
+	Resource string `json:"resource"`
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+type Assertion struct {
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	Name string `json:"name"`
+
+	// Built-in types or plugin types (kubetest-<type> executables)
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9-]*$`
+	Type string `json:"type"`
+
+	// +kubebuilder:validation:Pattern=`^(critical|warning|info)$`
+	// +optional
+	Severity string `json:"severity,omitempty"`
+
+	// +optional
+	Resource string `json:"resource,omitempty"`
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Label and field selectors, see docs/selectors.md
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Selectors *runtime.RawExtension `json:"selectors,omitempty"`
+
+	// +optional
+	Count int32 `json:"count,omitempty"`
+	// +optional
+	Min *int32 `json:"min,omitempty"`
+	// +optional
+	Max *int32 `json:"max,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(eq|ne|gt|ge|lt|le)$`
+	// +optional
+	Operator string `json:"operator,omitempty"`
+	// +optional
+	Absent bool `json:"absent,omitempty"`
+	// +optional
+	Stable string `json:"stable,omitempty"`
+
+	// +optional
+	Expression string `json:"expression,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(all|exists)$`
+	// +optional
+	Quantifier string `json:"quantifier,omitempty"`
+	// +optional
+	AllowEmpty bool `json:"allowEmpty,omitempty"`
+
+	// Free-form parameters passed to plugin assertions
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Params *runtime.RawExtension `json:"params,omitempty"`
+
+	// Sub-assertions of the composite types: allOf, anyOf, not
+	// +kubebuilder:validation:items:Type=object
+	// +kubebuilder:validation:items:XPreserveUnknownFields
+	// +optional
+	Assertions []Assertion `json:"assertions,omitempty"`
+
+	// +optional
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
+	// +optional
+	IncludeFields []string `json:"includeFields,omitempty"`
+
+	// +optional
+	Errors []string `json:"errors,omitempty"`
+	// +optional
+	MatchErrors []ErrorMatcher `json:"matchErrors,omitempty"`
+	// +optional
+	AllowExtraErrors bool `json:"allowExtraErrors,omitempty"`
+
+	// TestResource holding the manifests used by expectedAdmission and expectedState
+	// +optional
+	TestResource string `json:"testResource,omitempty"`
+	// +optional
+	Admission *AdmissionExpectation `json:"admission,omitempty"`
+}
+
+// Empty fields match anything, reason and message are regular expressions
+type ErrorMatcher struct {
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Code int32 `json:"code,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type AdmissionExpectation struct {
+	// +optional
+	Allowed bool `json:"allowed,omitempty"`
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	Code int32 `json:"code,omitempty"`
+}
+
+// A phase of a multi-step test
+type Step struct {
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// +optional
+	Apply []string `json:"apply,omitempty"`
+	// +optional
+	Patch []string `json:"patch,omitempty"`
+	// +optional
+	Delete []string `json:"delete,omitempty"`
+
+	// +optional
+	WaitFor []WaitFor `json:"waitFor,omitempty"`
+	// +optional
+	WaitForDeletion []WaitFor `json:"waitForDeletion,omitempty"`
+
+	// +optional
+	Assert []Assertion `json:"assert,omitempty"`
+
+	// +optional
+	Actions []ObjectAction `json:"actions,omitempty"`
+	// +optional
+	Chaos []ChaosAction `json:"chaos,omitempty"`
+}
+
+// Patch or delete an existing object
+type ObjectAction struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Pattern=`^(patch|delete)$`
+	Type string `json:"type"`
+
+	// apiVersion:Kind[:namespace]:name
+	Resource string `json:"resource"`
+
+	// +kubebuilder:validation:Pattern=`^(json|merge|strategic)$`
+	// +optional
+	PatchType string `json:"patchType,omitempty"`
+	// +optional
+	Patch string `json:"patch,omitempty"`
+}
+
+// A disruptive action, see docs/chaos.md
+type ChaosAction struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Pattern=`^(deletePods|evictPods|cordonNode|drainNode|scaleDeployment)$`
+	Type string `json:"type"`
+
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Selectors *runtime.RawExtension `json:"selectors,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Count int32 `json:"count,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// TestResource holds the manifests used by tests and fixtures
+//
+// +genclient
+// +genclient:noStatus
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=testresources,singular=testresource,shortName=trsc,scope=Namespaced
+type TestResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TestResourceSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type TestResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TestResource `json:"items"`
+}
+
+type TestResourceSpec struct {
+	// YAML manifests separated by ---
+	Data string `json:"data"`
+}
+
+// TestFixture holds resources shared by several tests
+//
+// +genclient
+// +genclient:noStatus
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=testfixtures,singular=testfixture,shortName=tfix,scope=Namespaced
+type TestFixture struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TestFixtureSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type TestFixtureList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TestFixture `json:"items"`
+}
+
+type TestFixtureSpec struct {
+	// Names of the TestResources created by setup
+	Resources []string `json:"resources"`
+
+	// +optional
+	Setup Phase `json:"setup,omitempty"`
+	// +optional
+	Teardown Phase `json:"teardown,omitempty"`
+}
+
+// TestResult is the result of the last run of a test
+//
+// +genclient
+// +genclient:noStatus
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=testresults,singular=testresult,shortName=tres,scope=Namespaced
+// +kubebuilder:printcolumn:name=Result,type=boolean,description=The result of the test,JSONPath=.spec.result
+// +kubebuilder:printcolumn:name=Skipped,type=boolean,description=Whether the test was skipped because a dependency didn't pass,JSONPath=.spec.skipped
+// +kubebuilder:printcolumn:name=Flaky,type=boolean,description=Whether the test passed on retry or flipped result in its recent runs,JSONPath=.spec.flaky
+type TestResult struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TestResultSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type TestResultList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TestResult `json:"items"`
+}
+
+type TestResultSpec struct {
+	Result bool `json:"result"`
+	// +optional
+	Skipped bool `json:"skipped,omitempty"`
+	// +optional
+	SkipReason string `json:"skipReason,omitempty"`
+
+	// Results of the assertions, by name
+	Assertions map[string]bool `json:"assertions"`
+	// Results of the sub-assertions of composite assertions, as <parent>.<sub-assertion>,
+	// only the composite assertions count
+	// +optional
+	SubAssertions map[string]bool `json:"subAssertions,omitempty"`
+	// +optional
+	Messages map[string]string `json:"messages,omitempty"`
+	// +optional
+	Severities map[string]string `json:"severities,omitempty"`
+	// Failed assertions below the critical severity
+	// +optional
+	Warnings []string `json:"warnings,omitempty"`
+	// +optional
+	Steps map[string]bool `json:"steps,omitempty"`
+
+	// Runs of the last execution, more than one if the test has been retried
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+	// Share of the recent runs that passed on retry or flipped result
+	// +optional
+	Flakiness float64 `json:"flakiness,omitempty"`
+	// +optional
+	Flaky bool `json:"flaky,omitempty"`
+	// Outcome of the recent runs, oldest first, the flakiness is computed on them
+	// +optional
+	History []RunOutcome `json:"history,omitempty"`
+
+	// Resources kept for debugging by the teardown policy, cleaned after RetainedUntil
+	// +optional
+	Retained []string `json:"retained,omitempty"`
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	RetainedUntil string `json:"retainedUntil,omitempty"`
+
+	// Summary of the diagnostics collected on failure, or the directory of the bundle
+	// +optional
+	Diagnostics string `json:"diagnostics,omitempty"`
+	// +optional
+	DiagnosticsPath string `json:"diagnosticsPath,omitempty"`
+
+	// Errors found while loading the test, the test failed without running
+	// +optional
+	LoadErrors []string `json:"loadErrors,omitempty"`
+}
+
+// Outcome of a run of a test, skipped runs aren't recorded
+type RunOutcome struct {
+	Passed bool `json:"passed"`
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+}
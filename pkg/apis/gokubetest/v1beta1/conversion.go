@@ -0,0 +1,562 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Member of the assertion union holding the parameters of the plugin types
+const pluginMember = "plugin"
+
+// Annotation holding the v1 fields dropped by ConvertFrom, by assertion path. ConvertTo restores them
+const droppedFieldsAnnotation = "go-kubetest.io/v1-fields"
+
+// v1 fields kept by every assertion type
+var commonFields = []string{"name", "type", "severity", "timeout"}
+
+// v1 fields of the members, by assertion type. The other types are plugins
+var memberFields = map[string][]string{
+	ExpectedResources:  {"resource", "selectors", "count", "min", "max", "operator", "absent", "stable"},
+	ExpectedErrors:     {"errors", "matchErrors", "allowExtraErrors"},
+	ExpectedAdmission:  {"testResource", "admission"},
+	ExpectedExpression: {"resource", "selectors", "expression", "quantifier", "allowEmpty"},
+	ExpectedState:      {"testResource", "ignoreFields", "includeFields"},
+	AllOf:              {"assertions"},
+	AnyOf:              {"assertions"},
+	Not:                {"assertions"},
+}
+
+var pluginFields = []string{"resource", "namespace", "selectors", "params"}
+
+// ConvertTo converts the TestDefinition to the storage version,
+// assertions with a member that doesn't match their type are errors.
+// The fields dropped by ConvertFrom are restored from the annotation
+func (in *TestDefinition) ConvertTo(out *v1.TestDefinition) error {
+
+	spec := in.Spec.DeepCopy()
+
+	out.TypeMeta = metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "TestDefinition"}
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	out.Spec = v1.TestDefinitionSpec{
+		Resources:      spec.Resources,
+		DependsOn:      spec.DependsOn,
+		Fixtures:       spec.Fixtures,
+		TeardownPolicy: spec.TeardownPolicy,
+		Retries:        spec.Retries,
+		Timeout:        spec.Timeout,
+	}
+	out.Status = v1.TestDefinitionStatus(*in.Status.DeepCopy())
+
+	// Required by v1
+	if out.Spec.Resources == nil {
+		out.Spec.Resources = []string{}
+	}
+	if spec.Setup != nil {
+		out.Spec.Setup.WaitFor = convertWaitForTo(spec.Setup.WaitFor)
+	}
+	if spec.Teardown != nil {
+		out.Spec.Teardown.WaitFor = convertWaitForTo(spec.Teardown.WaitFor)
+	}
+
+	var err error
+	out.Spec.Assert, err = convertAssertionsTo(spec.Assert)
+	if err != nil {
+		return err
+	}
+	if out.Spec.Assert == nil {
+		out.Spec.Assert = []v1.Assertion{}
+	}
+
+	for _, step := range spec.Steps {
+		converted, err := convertStepTo(step)
+		if err != nil {
+			return fmt.Errorf("step %s: %v", step.Name, err)
+		}
+		out.Spec.Steps = append(out.Spec.Steps, converted)
+	}
+
+	return restoreDroppedFields(out)
+}
+
+// ConvertFrom converts a TestDefinition from the storage version, empty phases are dropped.
+// The fields of the assertions that don't belong to their type are kept in an annotation
+func (out *TestDefinition) ConvertFrom(in *v1.TestDefinition) error {
+
+	spec := in.Spec.DeepCopy()
+
+	out.TypeMeta = metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "TestDefinition"}
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	out.Spec = TestDefinitionSpec{
+		Resources:      spec.Resources,
+		DependsOn:      spec.DependsOn,
+		Fixtures:       spec.Fixtures,
+		TeardownPolicy: spec.TeardownPolicy,
+		Retries:        spec.Retries,
+		Timeout:        spec.Timeout,
+		Assert:         convertAssertionsFrom(spec.Assert),
+	}
+	out.Status = TestDefinitionStatus(*in.Status.DeepCopy())
+
+	if len(spec.Setup.WaitFor) > 0 {
+		out.Spec.Setup = &Phase{WaitFor: convertWaitForFrom(spec.Setup.WaitFor)}
+	}
+	if len(spec.Teardown.WaitFor) > 0 {
+		out.Spec.Teardown = &Phase{WaitFor: convertWaitForFrom(spec.Teardown.WaitFor)}
+	}
+	if out.Spec.Assert == nil {
+		out.Spec.Assert = []Assertion{}
+	}
+
+	for _, step := range spec.Steps {
+		out.Spec.Steps = append(out.Spec.Steps, convertStepFrom(step))
+	}
+
+	return saveDroppedFields(in, out)
+}
+
+// Keep the v1 fields of the assertions that don't belong to their type in the annotation of out
+func saveDroppedFields(in *v1.TestDefinition, out *TestDefinition) error {
+
+	dropped := map[string]map[string]interface{}{}
+	err := collectDroppedFields("assert", in.Spec.Assert, dropped)
+	if err != nil {
+		return err
+	}
+	for _, step := range in.Spec.Steps {
+		err = collectDroppedFields("steps/"+step.Name, step.Assert, dropped)
+		if err != nil {
+			return err
+		}
+	}
+
+	if out.Annotations != nil {
+		delete(out.Annotations, droppedFieldsAnnotation)
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(dropped)
+	if err != nil {
+		return err
+	}
+	if out.Annotations == nil {
+		out.Annotations = map[string]string{}
+	}
+	out.Annotations[droppedFieldsAnnotation] = string(data)
+	return nil
+}
+
+// Restore the v1 fields saved by ConvertFrom, an assertion whose type changed doesn't get them back
+func restoreDroppedFields(out *v1.TestDefinition) error {
+
+	data, ok := out.Annotations[droppedFieldsAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(out.Annotations, droppedFieldsAnnotation)
+	if len(out.Annotations) == 0 {
+		out.Annotations = nil
+	}
+
+	dropped := map[string]map[string]interface{}{}
+	err := json.Unmarshal([]byte(data), &dropped)
+	if err != nil {
+		return fmt.Errorf("annotation %s: %v", droppedFieldsAnnotation, err)
+	}
+
+	err = restoreAssertionFields("assert", out.Spec.Assert, dropped)
+	if err != nil {
+		return err
+	}
+	for _, step := range out.Spec.Steps {
+		err = restoreAssertionFields("steps/"+step.Name, step.Assert, dropped)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectDroppedFields(prefix string, assertions []v1.Assertion, dropped map[string]map[string]interface{}) error {
+
+	for _, assertion := range assertions {
+		path := prefix + "/" + assertion.Name
+
+		fields, err := assertionFields(assertion)
+		if err != nil {
+			return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+		}
+		for _, field := range commonFields {
+			delete(fields, field)
+		}
+		for _, field := range typeFields(assertion.Type) {
+			delete(fields, field)
+		}
+		if len(fields) > 0 {
+			fields["type"] = assertion.Type
+			dropped[path] = fields
+		}
+
+		if isComposite(assertion.Type) {
+			err = collectDroppedFields(path, assertion.Assertions, dropped)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func restoreAssertionFields(prefix string, assertions []v1.Assertion, dropped map[string]map[string]interface{}) error {
+
+	for i := range assertions {
+		assertion := &assertions[i]
+		path := prefix + "/" + assertion.Name
+
+		if fields, ok := dropped[path]; ok && fields["type"] == assertion.Type {
+			merged, err := assertionFields(*assertion)
+			if err != nil {
+				return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+			}
+			for field, value := range fields {
+				if field != "type" {
+					merged[field] = value
+				}
+			}
+
+			data, err := json.Marshal(merged)
+			if err != nil {
+				return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+			}
+			*assertion = v1.Assertion{}
+			err = json.Unmarshal(data, assertion)
+			if err != nil {
+				return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+			}
+		}
+
+		if isComposite(assertion.Type) {
+			err := restoreAssertionFields(path, assertion.Assertions, dropped)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// The fields of a v1 assertion as they are serialized, empty fields are omitted
+func assertionFields(assertion v1.Assertion) (map[string]interface{}, error) {
+
+	data, err := json.Marshal(assertion)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+func typeFields(assertionType string) []string {
+
+	if fields, ok := memberFields[assertionType]; ok {
+		return fields
+	}
+	return pluginFields
+}
+
+func isComposite(assertionType string) bool {
+	return assertionType == AllOf || assertionType == AnyOf || assertionType == Not
+}
+
+func convertAssertionsTo(assertions []Assertion) ([]v1.Assertion, error) {
+
+	var converted []v1.Assertion
+	for _, assertion := range assertions {
+		out, err := convertAssertionTo(assertion)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, out)
+	}
+	return converted, nil
+}
+
+func convertAssertionTo(in Assertion) (v1.Assertion, error) {
+
+	out := v1.Assertion{
+		Name:     in.Name,
+		Type:     in.Type,
+		Severity: in.Severity,
+		Timeout:  in.Timeout,
+	}
+
+	if err := validateMembers(in); err != nil {
+		return out, err
+	}
+
+	var err error
+	switch in.Type {
+	case ExpectedResources:
+		member := in.ExpectedResources
+		out.Resource = member.Resource
+		out.Selectors = member.Selectors
+		out.Count = member.Count
+		out.Min = member.Min
+		out.Max = member.Max
+		out.Operator = member.Operator
+		out.Absent = member.Absent
+		out.Stable = member.Stable
+
+	case ExpectedErrors:
+		if member := in.ExpectedErrors; member != nil {
+			out.Errors = member.Errors
+			out.AllowExtraErrors = member.AllowExtraErrors
+			for _, matcher := range member.MatchErrors {
+				out.MatchErrors = append(out.MatchErrors, v1.ErrorMatcher(matcher))
+			}
+		}
+
+	case ExpectedAdmission:
+		member := in.ExpectedAdmission
+		out.TestResource = member.TestResource
+		out.Admission = &v1.AdmissionExpectation{
+			Allowed: member.Allowed,
+			Reason:  member.Reason,
+			Message: member.Message,
+			Code:    member.Code,
+		}
+
+	case ExpectedExpression:
+		member := in.ExpectedExpression
+		out.Resource = member.Resource
+		out.Selectors = member.Selectors
+		out.Expression = member.Expression
+		out.Quantifier = member.Quantifier
+		out.AllowEmpty = member.AllowEmpty
+
+	case ExpectedState:
+		member := in.ExpectedState
+		out.TestResource = member.TestResource
+		out.IgnoreFields = member.IgnoreFields
+		out.IncludeFields = member.IncludeFields
+
+	case AllOf:
+		out.Assertions, err = convertAssertionsTo(in.AllOf)
+
+	case AnyOf:
+		out.Assertions, err = convertAssertionsTo(in.AnyOf)
+
+	case Not:
+		out.Assertions, err = convertAssertionsTo([]Assertion{*in.Not})
+
+	default:
+		if member := in.Plugin; member != nil {
+			out.Resource = member.Resource
+			out.Namespace = member.Namespace
+			out.Selectors = member.Selectors
+			out.Params = member.Params
+		}
+	}
+
+	if err != nil {
+		return out, fmt.Errorf("assertion %s: %v", in.Name, err)
+	}
+	return out, nil
+}
+
+// Check that only the member of the type is set, and that it is set when the type has required parameters
+func validateMembers(in Assertion) error {
+
+	members := []struct {
+		name     string
+		set      bool
+		required bool
+	}{
+		{ExpectedResources, in.ExpectedResources != nil, true},
+		{ExpectedErrors, in.ExpectedErrors != nil, false},
+		{ExpectedAdmission, in.ExpectedAdmission != nil, true},
+		{ExpectedExpression, in.ExpectedExpression != nil, true},
+		{ExpectedState, in.ExpectedState != nil, true},
+		{AllOf, len(in.AllOf) > 0, true},
+		{AnyOf, len(in.AnyOf) > 0, true},
+		{Not, in.Not != nil, true},
+	}
+
+	expected := pluginMember
+	for _, member := range members {
+		if member.name == in.Type {
+			expected = member.name
+			if member.required && !member.set {
+				return fmt.Errorf("assertion %s: type %s requires %s", in.Name, in.Type, member.name)
+			}
+		}
+	}
+
+	for _, member := range members {
+		if member.set && member.name != expected {
+			return fmt.Errorf("assertion %s: %s is set, but the type is %s", in.Name, member.name, in.Type)
+		}
+	}
+	if in.Plugin != nil && expected != pluginMember {
+		return fmt.Errorf("assertion %s: %s is set, but the type is %s", in.Name, pluginMember, in.Type)
+	}
+
+	return nil
+}
+
+func convertAssertionsFrom(assertions []v1.Assertion) []Assertion {
+
+	var converted []Assertion
+	for _, assertion := range assertions {
+		converted = append(converted, convertAssertionFrom(assertion))
+	}
+	return converted
+}
+
+func convertAssertionFrom(in v1.Assertion) Assertion {
+
+	out := Assertion{
+		Name:     in.Name,
+		Type:     in.Type,
+		Severity: in.Severity,
+		Timeout:  in.Timeout,
+	}
+
+	switch in.Type {
+	case ExpectedResources:
+		out.ExpectedResources = &ResourcesAssertion{
+			Resource:  in.Resource,
+			Selectors: in.Selectors,
+			Count:     in.Count,
+			Min:       in.Min,
+			Max:       in.Max,
+			Operator:  in.Operator,
+			Absent:    in.Absent,
+			Stable:    in.Stable,
+		}
+
+	case ExpectedErrors:
+		out.ExpectedErrors = &ErrorsAssertion{
+			Errors:           in.Errors,
+			AllowExtraErrors: in.AllowExtraErrors,
+		}
+		for _, matcher := range in.MatchErrors {
+			out.ExpectedErrors.MatchErrors = append(out.ExpectedErrors.MatchErrors, ErrorMatcher(matcher))
+		}
+
+	case ExpectedAdmission:
+		out.ExpectedAdmission = &AdmissionAssertion{TestResource: in.TestResource}
+		if in.Admission != nil {
+			out.ExpectedAdmission.Allowed = in.Admission.Allowed
+			out.ExpectedAdmission.Reason = in.Admission.Reason
+			out.ExpectedAdmission.Message = in.Admission.Message
+			out.ExpectedAdmission.Code = in.Admission.Code
+		}
+
+	case ExpectedExpression:
+		out.ExpectedExpression = &ExpressionAssertion{
+			Resource:   in.Resource,
+			Selectors:  in.Selectors,
+			Expression: in.Expression,
+			Quantifier: in.Quantifier,
+			AllowEmpty: in.AllowEmpty,
+		}
+
+	case ExpectedState:
+		out.ExpectedState = &StateAssertion{
+			TestResource:  in.TestResource,
+			IgnoreFields:  in.IgnoreFields,
+			IncludeFields: in.IncludeFields,
+		}
+
+	case AllOf:
+		out.AllOf = convertAssertionsFrom(in.Assertions)
+
+	case AnyOf:
+		out.AnyOf = convertAssertionsFrom(in.Assertions)
+
+	case Not:
+		// More than one sub-assertion is invalid in v1 as well
+		if len(in.Assertions) > 0 {
+			not := convertAssertionFrom(in.Assertions[0])
+			out.Not = &not
+		}
+
+	default:
+		if in.Resource != "" || in.Namespace != "" || in.Selectors != nil || in.Params != nil {
+			out.Plugin = &PluginAssertion{
+				Resource:  in.Resource,
+				Namespace: in.Namespace,
+				Selectors: in.Selectors,
+				Params:    in.Params,
+			}
+		}
+	}
+
+	return out
+}
+
+func convertStepTo(in Step) (v1.Step, error) {
+
+	out := v1.Step{
+		Name:            in.Name,
+		Apply:           in.Apply,
+		Patch:           in.Patch,
+		Delete:          in.Delete,
+		WaitFor:         convertWaitForTo(in.WaitFor),
+		WaitForDeletion: convertWaitForTo(in.WaitForDeletion),
+	}
+	for _, action := range in.Actions {
+		out.Actions = append(out.Actions, v1.ObjectAction(action))
+	}
+	for _, action := range in.Chaos {
+		out.Chaos = append(out.Chaos, v1.ChaosAction(action))
+	}
+
+	var err error
+	out.Assert, err = convertAssertionsTo(in.Assert)
+	return out, err
+}
+
+func convertStepFrom(in v1.Step) Step {
+
+	out := Step{
+		Name:            in.Name,
+		Apply:           in.Apply,
+		Patch:           in.Patch,
+		Delete:          in.Delete,
+		WaitFor:         convertWaitForFrom(in.WaitFor),
+		WaitForDeletion: convertWaitForFrom(in.WaitForDeletion),
+		Assert:          convertAssertionsFrom(in.Assert),
+	}
+	for _, action := range in.Actions {
+		out.Actions = append(out.Actions, ObjectAction(action))
+	}
+	for _, action := range in.Chaos {
+		out.Chaos = append(out.Chaos, ChaosAction(action))
+	}
+	return out
+}
+
+func convertWaitForTo(waitFor []WaitFor) []v1.WaitFor {
+
+	var converted []v1.WaitFor
+	for _, item := range waitFor {
+		converted = append(converted, v1.WaitFor(item))
+	}
+	return converted
+}
+
+func convertWaitForFrom(waitFor []v1.WaitFor) []WaitFor {
+
+	var converted []WaitFor
+	for _, item := range waitFor {
+		converted = append(converted, WaitFor(item))
+	}
+	return converted
+}
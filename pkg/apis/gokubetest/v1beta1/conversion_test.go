@@ -0,0 +1,192 @@
+package v1beta1
+
+import (
+	"testing"
+
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvertTo(t *testing.T) {
+
+	max := int32(3)
+	in := &TestDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "default"},
+		Spec: TestDefinitionSpec{
+			Resources: []string{"webhook-manifests"},
+			Assert: []Assertion{
+				{
+					Name: "replicas",
+					Type: ExpectedResources,
+					ExpectedResources: &ResourcesAssertion{
+						Resource:  "apps/v1:Deployment:default",
+						Selectors: &runtime.RawExtension{Raw: []byte(`{"metadata.name":"webhook"}`)},
+						Max:       &max,
+					},
+				},
+				{
+					Name: "no-errors",
+					Type: Not,
+					Not: &Assertion{
+						Name:           "errors",
+						Type:           ExpectedErrors,
+						ExpectedErrors: &ErrorsAssertion{Errors: []string{"denied"}},
+					},
+				},
+				{
+					Name:   "custom",
+					Type:   "http-probe",
+					Plugin: &PluginAssertion{Params: &runtime.RawExtension{Raw: []byte(`{"url":"http://webhook"}`)}},
+				},
+			},
+		},
+	}
+
+	out := &v1.TestDefinition{}
+	err := in.ConvertTo(out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "go-kubetest.io/v1", out.APIVersion)
+	assert.Equal(t, "webhook", out.Name)
+	assert.Equal(t, v1.Phase{}, out.Spec.Setup)
+	assert.Equal(t, "apps/v1:Deployment:default", out.Spec.Assert[0].Resource)
+	assert.Equal(t, &max, out.Spec.Assert[0].Max)
+	assert.Equal(t, []string{"denied"}, out.Spec.Assert[1].Assertions[0].Errors)
+	assert.Equal(t, `{"url":"http://webhook"}`, string(out.Spec.Assert[2].Params.Raw))
+
+	// Back to v1beta1
+	back := &TestDefinition{}
+	err = back.ConvertFrom(out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "go-kubetest.io/v1beta1", back.APIVersion)
+	back.TypeMeta = in.TypeMeta
+	assert.Equal(t, in, back)
+}
+
+func TestConvertToMembers(t *testing.T) {
+
+	tests := map[string]Assertion{
+		"type expectedState requires expectedState": {
+			Name: "state",
+			Type: ExpectedState,
+		},
+		"expectedErrors is set, but the type is expectedResources": {
+			Name:              "resources",
+			Type:              ExpectedResources,
+			ExpectedResources: &ResourcesAssertion{Resource: "v1:Pod:default"},
+			ExpectedErrors:    &ErrorsAssertion{},
+		},
+		"plugin is set, but the type is allOf": {
+			Name:   "composite",
+			Type:   AllOf,
+			AllOf:  []Assertion{{Name: "errors", Type: ExpectedErrors}},
+			Plugin: &PluginAssertion{},
+		},
+		"expectedAdmission is set, but the type is http-probe": {
+			Name:              "custom",
+			Type:              "http-probe",
+			ExpectedAdmission: &AdmissionAssertion{TestResource: "manifests"},
+		},
+	}
+
+	for message, assertion := range tests {
+		in := &TestDefinition{Spec: TestDefinitionSpec{Assert: []Assertion{assertion}}}
+		err := in.ConvertTo(&v1.TestDefinition{})
+		if assert.NotNil(t, err, message) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
+
+func TestConvertFromPhases(t *testing.T) {
+
+	in := &v1.TestDefinition{
+		Spec: v1.TestDefinitionSpec{
+			Setup: v1.Phase{WaitFor: []v1.WaitFor{{Resource: "v1:Namespace:webhook", Timeout: "30s"}}},
+		},
+	}
+
+	out := &TestDefinition{}
+	err := out.ConvertFrom(in)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &Phase{WaitFor: []WaitFor{{Resource: "v1:Namespace:webhook", Timeout: "30s"}}}, out.Spec.Setup)
+	assert.Nil(t, out.Spec.Teardown)
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+
+	// The fields that don't belong to the type of the assertions are kept in the annotation
+	in := &v1.TestDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "go-kubetest.io/v1", Kind: "TestDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "default"},
+		Spec: v1.TestDefinitionSpec{
+			Resources: []string{"webhook-manifests"},
+			Assert: []v1.Assertion{
+				{
+					Name:       "replicas",
+					Type:       ExpectedResources,
+					Resource:   "apps/v1:Deployment:default",
+					Count:      1,
+					Expression: "object.spec.replicas == 1",
+					Params:     &runtime.RawExtension{Raw: []byte(`{"url":"http://webhook"}`)},
+				},
+				{
+					Name: "cni",
+					Type: AnyOf,
+					Assertions: []v1.Assertion{
+						{Name: "calico", Type: ExpectedExpression, Resource: "v1:Pod:kube-system", Expression: "true", IgnoreFields: []string{"status"}},
+					},
+				},
+			},
+			Steps: []v1.Step{
+				{
+					Name: "upgrade",
+					Assert: []v1.Assertion{
+						{Name: "admission", Type: ExpectedAdmission, TestResource: "pod", Admission: &v1.AdmissionExpectation{Allowed: true}, AllowExtraErrors: true},
+					},
+				},
+			},
+		},
+	}
+
+	beta := &TestDefinition{}
+	err := beta.ConvertFrom(in.DeepCopy())
+
+	assert.Nil(t, err)
+	assert.Nil(t, beta.Spec.Setup)
+	assert.Nil(t, beta.Spec.Teardown)
+	assert.Contains(t, beta.Annotations, droppedFieldsAnnotation)
+
+	out := &v1.TestDefinition{}
+	err = beta.ConvertTo(out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, in, out)
+}
+
+func TestConvertToChangedType(t *testing.T) {
+
+	// Fields saved for an assertion whose type changed in v1beta1 aren't restored
+	in := &TestDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "webhook",
+			Annotations: map[string]string{droppedFieldsAnnotation: `{"assert/replicas":{"type":"expectedExpression","count":1}}`},
+		},
+		Spec: TestDefinitionSpec{
+			Assert: []Assertion{
+				{Name: "replicas", Type: ExpectedResources, ExpectedResources: &ResourcesAssertion{Resource: "v1:Pod:default"}},
+			},
+		},
+	}
+
+	out := &v1.TestDefinition{}
+	err := in.ConvertTo(out)
+
+	assert.Nil(t, err)
+	assert.Nil(t, out.Annotations)
+	assert.Equal(t, int32(0), out.Spec.Assert[0].Count)
+}
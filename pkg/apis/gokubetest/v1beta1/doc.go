@@ -0,0 +1,10 @@
+// +kubebuilder:object:generate=true
+// +groupName=go-kubetest.io
+// +groupGoName=Kubetest
+
+// Package v1beta1 contains the types of the go-kubetest.io/v1beta1 API: TestDefinition
+// with optional setup and teardown and assertions structured by type.
+//
+// Objects are stored as v1, the conversion webhook converts them with ConvertTo and ConvertFrom.
+// The deepcopy functions and the CRDs in crds/ are generated from these types by hack/update-codegen.sh.
+package v1beta1
@@ -0,0 +1,336 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Assertion types with a member in Assertion, the other types are plugins
+const (
+	ExpectedResources  = "expectedResources"
+	ExpectedErrors     = "expectedErrors"
+	ExpectedAdmission  = "expectedAdmission"
+	ExpectedExpression = "expectedExpression"
+	ExpectedState      = "expectedState"
+	AllOf              = "allOf"
+	AnyOf              = "anyOf"
+	Not                = "not"
+)
+
+// TestDefinition describes a test: the resources to create, the assertions to run and the steps
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=testdefinitions,singular=testdefinition,shortName=tdef,scope=Namespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name=Result,type=string,description=The result of the last run,JSONPath=.status.lastResult
+// +kubebuilder:printcolumn:name="Last Run",type=date,description=When the test last ran,JSONPath=.status.lastRunTime
+// +kubebuilder:printcolumn:name="Next Run",type=date,description=When the test runs next,JSONPath=.status.nextRunTime
+// +kubebuilder:printcolumn:name=Age,type=date,JSONPath=.metadata.creationTimestamp
+// +kubebuilder:unservedversion
+type TestDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TestDefinitionSpec `json:"spec"`
+	// +optional
+	Status TestDefinitionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type TestDefinitionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TestDefinition `json:"items"`
+}
+
+type TestDefinitionSpec struct {
+	// Names of the TestResources created by setup
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+
+	// +optional
+	Setup *Phase `json:"setup,omitempty"`
+	// +optional
+	Teardown *Phase `json:"teardown,omitempty"`
+
+	// Names of the tests that have to pass before this one runs
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Names of the TestFixtures set up before this test
+	// +optional
+	Fixtures []string `json:"fixtures,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^(always|onSuccess|never)$`
+	// +optional
+	TeardownPolicy string `json:"teardownPolicy,omitempty"`
+
+	// How many times a failed test runs again
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// Max duration of setup, assertions and steps
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	Assert []Assertion `json:"assert"`
+
+	// +optional
+	Steps []Step `json:"steps,omitempty"`
+}
+
+// Status of the last run of a test, set by the controller
+type TestDefinitionStatus struct {
+	// One of: Passed, Failed, Skipped
+	// +optional
+	LastResult string `json:"lastResult,omitempty"`
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	LastRunTime string `json:"lastRunTime,omitempty"`
+	// Empty when the controller runs once
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	NextRunTime string `json:"nextRunTime,omitempty"`
+	// Errors found while loading the test, e.g. missing TestResources. The test failed without running
+	// +optional
+	LoadErrors []string `json:"loadErrors,omitempty"`
+}
+
+// Resources waited for at the end of setup or teardown
+type Phase struct {
+	// +optional
+	WaitFor []WaitFor `json:"waitFor,omitempty"`
+}
+
+type WaitFor struct {
+	// apiVersion:Kind[:namespace]:name
+	Resource string `json:"resource"`
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Assertion is a union discriminated by type: the parameters are in the member named
+// after the type, the other members have to be empty. Plugin types use plugin
+type Assertion struct {
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	Name string `json:"name"`
+
+	// Built-in types or plugin types (kubetest-<type> executables)
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9-]*$`
+	// +unionDiscriminator
+	Type string `json:"type"`
+
+	// +kubebuilder:validation:Pattern=`^(critical|warning|info)$`
+	// +optional
+	Severity string `json:"severity,omitempty"`
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// +optional
+	ExpectedResources *ResourcesAssertion `json:"expectedResources,omitempty"`
+	// +optional
+	ExpectedErrors *ErrorsAssertion `json:"expectedErrors,omitempty"`
+	// +optional
+	ExpectedAdmission *AdmissionAssertion `json:"expectedAdmission,omitempty"`
+	// +optional
+	ExpectedExpression *ExpressionAssertion `json:"expectedExpression,omitempty"`
+	// +optional
+	ExpectedState *StateAssertion `json:"expectedState,omitempty"`
+	// +kubebuilder:validation:items:Type=object
+	// +kubebuilder:validation:items:XPreserveUnknownFields
+	// +optional
+	AllOf []Assertion `json:"allOf,omitempty"`
+	// +kubebuilder:validation:items:Type=object
+	// +kubebuilder:validation:items:XPreserveUnknownFields
+	// +optional
+	AnyOf []Assertion `json:"anyOf,omitempty"`
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Not *Assertion `json:"not,omitempty"`
+	// +optional
+	Plugin *PluginAssertion `json:"plugin,omitempty"`
+}
+
+// Count the objects matching the selectors
+type ResourcesAssertion struct {
+	// apiVersion:Kind[:namespace]
+	Resource string `json:"resource"`
+
+	// Label and field selectors, see docs/selectors.md
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Selectors *runtime.RawExtension `json:"selectors,omitempty"`
+
+	// +optional
+	Count int32 `json:"count,omitempty"`
+	// +optional
+	Min *int32 `json:"min,omitempty"`
+	// +optional
+	Max *int32 `json:"max,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(eq|ne|gt|ge|lt|le)$`
+	// +optional
+	Operator string `json:"operator,omitempty"`
+	// +optional
+	Absent bool `json:"absent,omitempty"`
+	// +optional
+	Stable string `json:"stable,omitempty"`
+}
+
+// Match the errors returned during setup
+type ErrorsAssertion struct {
+	// +optional
+	Errors []string `json:"errors,omitempty"`
+	// +optional
+	MatchErrors []ErrorMatcher `json:"matchErrors,omitempty"`
+	// +optional
+	AllowExtraErrors bool `json:"allowExtraErrors,omitempty"`
+}
+
+// Empty fields match anything, reason and message are regular expressions
+type ErrorMatcher struct {
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Code int32 `json:"code,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Dry-run the manifests of a TestResource and match the admission response
+type AdmissionAssertion struct {
+	TestResource string `json:"testResource"`
+
+	// +optional
+	Allowed bool `json:"allowed,omitempty"`
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	Code int32 `json:"code,omitempty"`
+}
+
+// Evaluate a CEL expression on the objects matching the selectors
+type ExpressionAssertion struct {
+	// apiVersion:Kind[:namespace]
+	Resource string `json:"resource"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Selectors *runtime.RawExtension `json:"selectors,omitempty"`
+
+	Expression string `json:"expression"`
+	// +kubebuilder:validation:Pattern=`^(all|exists)$`
+	// +optional
+	Quantifier string `json:"quantifier,omitempty"`
+	// Pass the all quantifier when no object is selected
+	// +optional
+	AllowEmpty bool `json:"allowEmpty,omitempty"`
+}
+
+// Compare the live objects with the manifests of a TestResource
+type StateAssertion struct {
+	TestResource string `json:"testResource"`
+
+	// +optional
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
+	// +optional
+	IncludeFields []string `json:"includeFields,omitempty"`
+}
+
+// Parameters of the assertions run by plugins
+type PluginAssertion struct {
+	// +optional
+	Resource string `json:"resource,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Selectors *runtime.RawExtension `json:"selectors,omitempty"`
+
+	// Free-form parameters passed to the plugin
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Params *runtime.RawExtension `json:"params,omitempty"`
+}
+
+// A phase of a multi-step test
+type Step struct {
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// +optional
+	Apply []string `json:"apply,omitempty"`
+	// +optional
+	Patch []string `json:"patch,omitempty"`
+	// +optional
+	Delete []string `json:"delete,omitempty"`
+
+	// +optional
+	WaitFor []WaitFor `json:"waitFor,omitempty"`
+	// +optional
+	WaitForDeletion []WaitFor `json:"waitForDeletion,omitempty"`
+
+	// +optional
+	Assert []Assertion `json:"assert,omitempty"`
+
+	// +optional
+	Actions []ObjectAction `json:"actions,omitempty"`
+	// +optional
+	Chaos []ChaosAction `json:"chaos,omitempty"`
+}
+
+// Patch or delete an existing object
+type ObjectAction struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Pattern=`^(patch|delete)$`
+	Type string `json:"type"`
+
+	// apiVersion:Kind[:namespace]:name
+	Resource string `json:"resource"`
+
+	// +kubebuilder:validation:Pattern=`^(json|merge|strategic)$`
+	// +optional
+	PatchType string `json:"patchType,omitempty"`
+	// +optional
+	Patch string `json:"patch,omitempty"`
+}
+
+// A disruptive action, see docs/chaos.md
+type ChaosAction struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Pattern=`^(deletePods|evictPods|cordonNode|drainNode|scaleDeployment)$`
+	Type string `json:"type"`
+
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Selectors *runtime.RawExtension `json:"selectors,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Count int32 `json:"count,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
@@ -2,95 +2,428 @@ package assert
 
 import (
 	"context"
-	"regexp"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ish-xyz/go-kubetest/pkg/expression"
+	"github.com/ish-xyz/go-kubetest/pkg/internal/helpers"
 	"github.com/ish-xyz/go-kubetest/pkg/loader"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func NewAssert(prv provisioner.Provisioner) *Assert {
-	return &Assert{
+
+	a := &Assert{
 		Provisioner: prv,
+		Registry:    NewRegistry(),
+	}
+	a.registerBuiltins()
+
+	return a
+}
+
+// Register the built-in assertion types, the registry is empty so it can't fail
+func (a *Assert) registerBuiltins() {
+
+	builtins := map[string]CheckerFunc{
+		"expectedResources":  expectedResources,
+		"expectedErrors":     expectedErrors,
+		"expectedAdmission":  expectedAdmission,
+		"expectedExpression": expectedExpression,
+		"expectedState":      expectedState,
+		"allOf":              a.allOf,
+		"anyOf":              a.anyOf,
+		"not":                a.not,
 	}
+	for assertionType, checker := range builtins {
+		a.Registry.Register(assertionType, checker)
+	}
+}
+
+func (a *Assert) Run(ctx context.Context, test *loader.TestDefinition, errors []provisioner.ObjectError) (bool, map[string]Result) {
+	return a.RunAssertions(ctx, test.Assert, errors)
 }
 
-func (a *Assert) Run(test *loader.TestDefinition, errors []string) (bool, map[string]interface{}) {
+// Run a list of assertions (e.g. the ones of a step), errors are the ones returned by its setup
+func (a *Assert) RunAssertions(ctx context.Context, assertions []loader.Assertion, errors []provisioner.ObjectError) (bool, map[string]Result) {
 
 	testResult := true
-	assertResults := map[string]interface{}{}
-	for _, assertion := range test.Assert {
-		var assertRes bool
+	assertResults := map[string]Result{}
+	ctx = WithSetupErrors(ctx, errors)
 
-		switch assertion.Type {
-		case "expectedResources":
-			assertRes = expectedResources(a.Provisioner, assertion)
-		case "expectedErrors":
-			assertRes = expectedErrors(assertion.Errors, errors)
+	for _, assertion := range assertions {
+		assertRes := a.runAssertion(ctx, assertion, assertion.Name, loader.SeverityCritical, assertResults)
+		if !assertRes.Passed && assertRes.Severity == loader.SeverityCritical {
+			testResult = false
 		}
+	}
 
-		if !assertRes {
-			testResult = false
+	return testResult, assertResults
+}
+
+// Run a single assertion and record its result with the given key,
+// assertions without severity inherit the one of their parent
+func (a *Assert) runAssertion(
+	ctx context.Context,
+	assertion loader.Assertion,
+	key string,
+	severity string,
+	assertResults map[string]Result,
+) Result {
+
+	var assertRes Result
+
+	if assertion.Severity != "" {
+		severity = assertion.Severity
+	}
+
+	checker, ok := a.Registry.Get(assertion.Type)
+	switch {
+	case ok:
+		state := &runState{
+			key:      key,
+			severity: severity,
+			results:  assertResults,
+		}
+		assertRes = checker.Check(context.WithValue(ctx, runStateKey, state), a.Provisioner, assertion)
+	case a.Plugins.Has(assertion.Type):
+		assertRes = a.runPlugin(ctx, assertion)
+	default:
+		assertRes = Result{Message: fmt.Sprintf("unknown assertion type '%s'", assertion.Type)}
+	}
+
+	assertRes.Severity = severity
+	assertResults[key] = assertRes
+	return assertRes
+}
+
+// Run the sub-assertions of a composite assertion, each sub-assertion
+// is still recorded individually as <parent>.<sub-assertion>, marked as nested
+func (a *Assert) runSubAssertions(ctx context.Context, assertion loader.Assertion) ([]string, []string) {
+
+	var passed, failed []string
+
+	// Called outside of Run (e.g. by an embedding checker), results are not recorded
+	state, ok := ctx.Value(runStateKey).(*runState)
+	if !ok {
+		state = &runState{
+			key:      assertion.Name,
+			severity: loader.SeverityCritical,
+			results:  map[string]Result{},
 		}
+	}
 
-		assertResults[assertion.Name] = assertRes
+	for _, subAssertion := range assertion.Assertions {
+		subKey := fmt.Sprintf("%s.%s", state.key, subAssertion.Name)
+		subRes := a.runAssertion(ctx, subAssertion, subKey, state.severity, state.results)
+		subRes.Nested = true
+		state.results[subKey] = subRes
+		if subRes.Passed {
+			passed = append(passed, subKey)
+			continue
+		}
+		failed = append(failed, subKey)
 	}
 
-	// TODO return bool []map[string]bool
-	return testResult, assertResults
+	return passed, failed
+}
+
+// Passes if every sub-assertion passes
+func (a *Assert) allOf(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+
+	_, failed := a.runSubAssertions(ctx, assertion)
+	if len(assertion.Assertions) > 0 && len(failed) == 0 {
+		return Result{Passed: true}
+	}
+	return Result{Message: fmt.Sprintf("failed sub-assertions: %s", strings.Join(failed, ", "))}
+}
+
+// Passes if at least one sub-assertion passes
+func (a *Assert) anyOf(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+
+	passed, failed := a.runSubAssertions(ctx, assertion)
+	if len(passed) > 0 {
+		return Result{Passed: true}
+	}
+	return Result{Message: fmt.Sprintf("no sub-assertion passed: %s", strings.Join(failed, ", "))}
+}
+
+// Passes if the only sub-assertion fails
+func (a *Assert) not(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+
+	if len(assertion.Assertions) != 1 {
+		return Result{Message: "'not' requires exactly one sub-assertion"}
+	}
+
+	passed, _ := a.runSubAssertions(ctx, assertion)
+	if len(passed) == 1 {
+		return Result{Message: fmt.Sprintf("sub-assertion passed: %s", passed[0])}
+	}
+	return Result{Passed: true}
+}
+
+// Dispatch the assertion to the external executable registered for its type
+func (a *Assert) runPlugin(ctx context.Context, assertion loader.Assertion) Result {
+
+	var result Result
+
+	output, stderr, err := a.Plugins.Run(ctx, assertion.Type, assertion, assertion.Timeout)
+	if err != nil {
+		result = Result{Message: err.Error()}
+	} else {
+		result = Result{Passed: output.Passed, Message: output.Message}
+	}
+
+	if stderr != "" {
+		result.Message = strings.TrimSpace(fmt.Sprintf("%s\nstderr: %s", result.Message, stderr))
+	}
+	return result
+}
+
+// Check if the errors throwed during setup are expected or not,
+// each matcher has to match a different error, in any order
+func expectedErrors(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+
+	actErrors := SetupErrors(ctx)
+	matchers := assertion.MatchErrors
+	for _, errorMessage := range assertion.Errors {
+		matchers = append(matchers, loader.ErrorMatcher{Message: errorMessage})
+	}
+
+	passed := len(matchers) <= len(actErrors) && matchAllErrors(matchers, actErrors)
+	if !assertion.AllowExtraErrors && len(matchers) != len(actErrors) {
+		passed = false
+	}
+	if passed {
+		return Result{Passed: true}
+	}
+
+	if len(actErrors) == 0 {
+		return Result{Message: "setup didn't return any error"}
+	}
+	messages := make([]string, len(actErrors))
+	for index, objErr := range actErrors {
+		messages[index] = objErr.Error()
+	}
+	return Result{Message: fmt.Sprintf("setup errors don't match the expected ones: %s", strings.Join(messages, "; "))}
 }
 
-// Check if the errors throwed during setup are expected or not
-func expectedErrors(expErrors, actErrors []string) bool {
+// Check if the manifests are allowed or denied by the admission chain,
+// objects are submitted with server-side dry-run so nothing is persisted
+func expectedAdmission(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+
+	if len(assertion.Objects) == 0 {
+		return Result{Message: fmt.Sprintf("no manifests loaded from test resource '%s'", assertion.TestResource)}
+	}
 
-	if len(expErrors) != len(actErrors) {
+	for _, obj := range assertion.Objects {
+		err := prv.DryRun(ctx, obj)
+		if admissionMatches(assertion.Admission, obj, err) {
+			continue
+		}
+		if err == nil {
+			return Result{Message: fmt.Sprintf("%s has been allowed", helpers.ObjectPath(obj))}
+		}
+		return Result{Message: fmt.Sprintf("unexpected admission response for %s: %v", helpers.ObjectPath(obj), err)}
+	}
+	return Result{Passed: true}
+}
+
+// Check if the dry-run response matches the expected admission outcome
+func admissionMatches(expected loader.AdmissionExpectation, obj *unstructured.Unstructured, err error) bool {
+
+	if expected.Allowed {
+		return err == nil
+	}
+	if err == nil {
 		return false
 	}
 
-	for index, errorMessage := range expErrors {
-		match, _ := regexp.MatchString(errorMessage, actErrors[index])
-		if !match {
-			return false
+	// A denial must come from the API server, anything else (e.g. an unknown kind) is a failure
+	objErr := provisioner.NewObjectError(obj, err)
+	if objErr.Code == 0 {
+		return false
+	}
+
+	return errorMatches(
+		loader.ErrorMatcher{
+			Reason:  expected.Reason,
+			Code:    expected.Code,
+			Message: expected.Message,
+		},
+		objErr,
+	)
+}
+
+// Check if the CEL expression holds for the selected objects,
+// the quantifier decides if all objects or at least one have to satisfy it
+func expectedExpression(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+
+	prg, err := expression.Compile(assertion.Expression)
+	if err != nil {
+		return Result{Message: fmt.Sprintf("invalid expression: %v", err)}
+	}
+
+	gvkData, err := helpers.ParseResource(assertion.Resource)
+	if err != nil {
+		return Result{Message: err.Error()}
+	}
+
+	var result Result
+	interval := 2
+	limit := helpers.MaxRetries(assertion.Timeout, interval)
+
+	for x := 0; x < limit; x++ {
+
+		objects, err := prv.ListWithSelectors(
+			ctx,
+			gvkData,
+			assertion.Selectors,
+		)
+		if err != nil {
+			result = Result{Message: fmt.Sprintf("can't list resources: %v", err)}
+		} else {
+			result = evalExpression(prg, assertion.Quantifier, assertion.AllowEmpty, objects.Items)
+		}
+
+		if result.Passed {
+			break
+		}
+
+		logrus.Debugln(result.Message)
+		logrus.Debugln("retrying to fetch resources during assertion 'expectedExpression' ...")
+		if !helpers.Sleep(ctx, interval) {
+			break
 		}
 	}
-	return true
+
+	return result
 }
 
-// Check if the retrieved objects match the expected count
-func expectedResources(prv provisioner.Provisioner, assertion loader.Assertion) bool {
+// Check if the live objects are a superset of the expected manifests,
+// mismatches are reported as a unified diff between expected and live state
+func expectedState(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
 
-	apiVersion, kind, namespace, err := unpackResource(assertion.Resource)
+	if len(assertion.Objects) == 0 {
+		return Result{Message: fmt.Sprintf("no manifests loaded from test resource '%s'", assertion.TestResource)}
+	}
+
+	ignored := getIgnoredFields(assertion.IgnoreFields, assertion.IncludeFields)
+
+	var result Result
+	interval := 2
+	limit := helpers.MaxRetries(assertion.Timeout, interval)
+
+	for x := 0; x < limit; x++ {
+
+		var diffs []string
+		for _, obj := range assertion.Objects {
+			live, err := prv.Get(ctx, obj)
+			if err != nil {
+				diffs = append(diffs, fmt.Sprintf("can't get %s: %v", helpers.ObjectPath(obj), err))
+				continue
+			}
+
+			diff, err := stateDiff(obj, live, ignored)
+			if err != nil {
+				diffs = append(diffs, fmt.Sprintf("can't compare %s: %v", helpers.ObjectPath(obj), err))
+				continue
+			}
+			if diff != "" {
+				diffs = append(diffs, diff)
+			}
+		}
+
+		result = Result{Passed: len(diffs) == 0, Message: strings.Join(diffs, "\n")}
+		if result.Passed {
+			break
+		}
+
+		logrus.Debugln(result.Message)
+		logrus.Debugln("retrying to fetch resources during assertion 'expectedState' ...")
+		if !helpers.Sleep(ctx, interval) {
+			break
+		}
+	}
+
+	return result
+}
+
+// Check if the retrieved objects match the expected count,
+// optionally requiring the condition to hold for the whole stable window
+func expectedResources(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+
+	gvkData, err := helpers.ParseResource(assertion.Resource)
 	if err != nil {
-		logrus.Warningf("assertion %s failed with error: %v", assertion.Name, err)
-		return false
+		return Result{Message: err.Error()}
+	}
+
+	// Validate the comparison before querying the API
+	if _, err := countMatches(assertion, 0); err != nil {
+		return Result{Message: err.Error()}
+	}
+
+	stable, err := getStableWindow(assertion.Stable)
+	if err != nil {
+		return Result{Message: fmt.Sprintf("invalid stable window: %v", err)}
 	}
 
-	passed, interval := false, 2
-	limit := getMaxRetries(assertion.Timeout, interval)
+	var lastErr error
+	var holdingSince time.Time
+	passed, lastCount, interval := false, 0, 2
+	limit := helpers.MaxRetries(assertion.Timeout, interval)
 
 	for x := 0; x < limit; x++ {
 
 		objects, err := prv.ListWithSelectors(
-			context.TODO(),
-			map[string]string{
-				"apiVersion": apiVersion,
-				"kind":       kind,
-				"namespace":  namespace,
-			},
+			ctx,
+			gvkData,
 			assertion.Selectors,
 		)
 
-		if err != nil || len(objects.Items) != assertion.Count {
+		// API errors are never treated as an empty result
+		lastErr = err
+		matched := false
+		if err == nil {
+			lastCount = len(objects.Items)
+			matched, _ = countMatches(assertion, lastCount)
+		}
+
+		if !matched {
+			holdingSince = time.Time{}
 			logrus.Debugln(err)
-			logrus.Debugln("retrying to fetch resources during assertion 'expectedErrors' ...")
-			time.Sleep(time.Duration(interval) * time.Second)
+			logrus.Debugln("retrying to fetch resources during assertion 'expectedResources' ...")
+			if !helpers.Sleep(ctx, interval) {
+				break
+			}
 			continue
 		}
 
-		passed = true
-		break
+		if holdingSince.IsZero() {
+			holdingSince = time.Now()
+		}
+		if time.Since(holdingSince) >= stable {
+			passed = true
+			break
+		}
+
+		logrus.Debugf("assertion %s holding since %s, waiting for stable window %s", assertion.Name, holdingSince, stable)
+		if !helpers.Sleep(ctx, interval) {
+			break
+		}
 	}
 
-	return passed
+	if passed {
+		return Result{Passed: true}
+	}
+	if lastErr != nil {
+		return Result{Message: fmt.Sprintf("last API error: %v", lastErr)}
+	}
+	if !holdingSince.IsZero() {
+		return Result{Message: fmt.Sprintf("condition didn't hold for %s", stable)}
+	}
+	return Result{Message: fmt.Sprintf("unexpected number of resources: %d", lastCount)}
 }
@@ -3,42 +3,21 @@ package assert
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/plugin"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // TODO
 
-func TestGetMaxRetries(t *testing.T) {
-	res := getMaxRetries("20s", 2)
-
-	assert.Equal(t, 10, res)
-}
-
-func TestUnpackResourceClusterWide(t *testing.T) {
-
-	version, kind, namespace, err := unpackResource("v1:Namespace")
-
-	assert.Equal(t, "v1", version)
-	assert.Equal(t, "Namespace", kind)
-	assert.Equal(t, "", namespace)
-	assert.Nil(t, err)
-}
-
-func TestUnpackResourceNamespaced(t *testing.T) {
-
-	version, kind, namespace, err := unpackResource("v1:Pod:default")
-
-	assert.Equal(t, "v1", version)
-	assert.Equal(t, "Pod", kind)
-	assert.Equal(t, "default", namespace)
-	assert.Nil(t, err)
-}
-
 func TestExpectedResources(t *testing.T) {
 
 	retObjects := &unstructured.UnstructuredList{
@@ -74,9 +53,9 @@ func TestExpectedResources(t *testing.T) {
 		Count:   1,
 	}
 
-	res := expectedResources(prvMock, asrt)
+	res := expectedResources(context.TODO(), prvMock, asrt)
 
-	assert.True(t, res)
+	assert.True(t, res.Passed)
 	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 1)
 }
 
@@ -115,9 +94,9 @@ func TestExpectedResourcesFailed(t *testing.T) {
 		Count:   100000,
 	}
 
-	res := expectedResources(prvMock, asrt)
+	res := expectedResources(context.TODO(), prvMock, asrt)
 
-	assert.False(t, res)
+	assert.False(t, res.Passed)
 	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 3)
 }
 
@@ -156,9 +135,9 @@ func TestExpectedResourcesFailedWithErrors(t *testing.T) {
 		Count:   1,
 	}
 
-	res := expectedResources(prvMock, asrt)
+	res := expectedResources(context.TODO(), prvMock, asrt)
 
-	assert.False(t, res)
+	assert.False(t, res.Passed)
 	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 3)
 }
 
@@ -174,41 +153,749 @@ func TestExpectedResourcesWrongPath(t *testing.T) {
 		Count:   100000,
 	}
 
-	res := expectedResources(prvMock, asrt)
+	res := expectedResources(context.TODO(), prvMock, asrt)
 
-	assert.False(t, res)
+	assert.False(t, res.Passed)
 	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 0)
 }
 
 func TestExpectedErrorsRegex(t *testing.T) {
 
-	expErrors := []string{".*SecurityContext.*"}
-	actErrors := []string{"something SecurityContext something"}
+	asrt := loader.Assertion{Errors: []string{".*SecurityContext.*"}}
+	actErrors := []provisioner.ObjectError{{Message: "something SecurityContext something"}}
 
-	res := expectedErrors(expErrors, actErrors)
+	res := expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt)
 
-	assert.True(t, res)
+	assert.True(t, res.Passed)
 
 }
 
 func TestExpectedErrors(t *testing.T) {
 
-	expErrors := []string{"something SecurityContext something"}
-	actErrors := []string{"something SecurityContext something"}
+	asrt := loader.Assertion{Errors: []string{"something SecurityContext something"}}
+	actErrors := []provisioner.ObjectError{{Message: "something SecurityContext something"}}
 
-	res := expectedErrors(expErrors, actErrors)
+	res := expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt)
 
-	assert.True(t, res)
+	assert.True(t, res.Passed)
 
 }
 
 func TestExpectedErrorsFailed(t *testing.T) {
 
-	expErrors := []string{}
-	actErrors := []string{"some random error"}
+	asrt := loader.Assertion{Errors: []string{}}
+	actErrors := []provisioner.ObjectError{{Message: "some random error"}}
+
+	res := expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt)
+
+	assert.False(t, res.Passed)
+
+}
+
+func TestExpectedErrorsAnyOrder(t *testing.T) {
+
+	asrt := loader.Assertion{Errors: []string{"quota exceeded", "already exists"}}
+	actErrors := []provisioner.ObjectError{
+		{Message: "pods \"nginx\" already exists"},
+		{Message: "exceeded quota: compute-resources, quota exceeded"},
+	}
+
+	assert.True(t, expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt).Passed)
+}
+
+func TestExpectedErrorsMatchPerObject(t *testing.T) {
+
+	actErrors := []provisioner.ObjectError{
+		{Kind: "Pod", Name: "privileged", Reason: "Forbidden", Code: 403, Message: "denied by policy"},
+		{Kind: "Pod", Name: "root", Reason: "Forbidden", Code: 403, Message: "denied by policy"},
+	}
+
+	// A generic matcher listed first must not steal the error of the specific one
+	asrt := loader.Assertion{
+		MatchErrors: []loader.ErrorMatcher{
+			{Kind: "Pod", Reason: "Forbidden"},
+			{Kind: "Pod", Name: "privileged", Code: 403, Message: ".*policy.*"},
+		},
+	}
+	assert.True(t, expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt).Passed)
+
+	// Wrong status code
+	asrt.MatchErrors[1].Code = 422
+	assert.False(t, expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt).Passed)
+
+	// Both matchers can only match the same object
+	asrt.MatchErrors = []loader.ErrorMatcher{{Name: "root"}, {Name: "root"}}
+	assert.False(t, expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt).Passed)
+}
+
+func TestExpectedErrorsAllowExtraErrors(t *testing.T) {
+
+	actErrors := []provisioner.ObjectError{
+		{Kind: "Pod", Name: "privileged", Reason: "Forbidden", Code: 403},
+		{Kind: "ConfigMap", Name: "settings", Reason: "AlreadyExists", Code: 409},
+	}
+
+	asrt := loader.Assertion{
+		MatchErrors: []loader.ErrorMatcher{{Kind: "Pod", Reason: "Forbidden"}},
+	}
+	assert.False(t, expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt).Passed)
+
+	asrt.AllowExtraErrors = true
+	assert.True(t, expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt).Passed)
 
-	res := expectedErrors(expErrors, actErrors)
+	// Extra errors are allowed, missing ones are not
+	asrt.MatchErrors = append(asrt.MatchErrors, loader.ErrorMatcher{Kind: "Secret"})
+	assert.False(t, expectedErrors(WithSetupErrors(context.TODO(), actErrors), nil, asrt).Passed)
+}
+
+func TestExpectedAdmissionAllowed(t *testing.T) {
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name": "compliant-pod",
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("DryRun", context.TODO(), obj).Return(nil)
 
+	asrt := loader.Assertion{
+		Name:    "allowed",
+		Objects: []*unstructured.Unstructured{obj},
+		Admission: loader.AdmissionExpectation{
+			Allowed: true,
+		},
+	}
+
+	res := expectedAdmission(context.TODO(), prvMock, asrt)
+
+	assert.True(t, res.Passed)
+	prvMock.AssertNumberOfCalls(t, "DryRun", 1)
+}
+
+func TestExpectedAdmissionDenied(t *testing.T) {
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name": "privileged-pod",
+			},
+		},
+	}
+
+	denied := apierrors.NewForbidden(
+		schema.GroupResource{Resource: "pods"},
+		"privileged-pod",
+		fmt.Errorf("violates PodSecurity \"restricted:latest\": privileged"),
+	)
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("DryRun", context.TODO(), obj).Return(denied)
+
+	asrt := loader.Assertion{
+		Name:    "denied",
+		Objects: []*unstructured.Unstructured{obj},
+		Admission: loader.AdmissionExpectation{
+			Allowed: false,
+			Reason:  "Forbidden",
+			Message: ".*PodSecurity.*",
+			Code:    403,
+		},
+	}
+
+	assert.True(t, expectedAdmission(context.TODO(), prvMock, asrt).Passed)
+
+	// Wrong status code
+	asrt.Admission.Code = 400
+	assert.False(t, expectedAdmission(context.TODO(), prvMock, asrt).Passed)
+
+	// Wrong message
+	asrt.Admission.Code = 403
+	asrt.Admission.Message = ".*Gatekeeper.*"
+	assert.False(t, expectedAdmission(context.TODO(), prvMock, asrt).Passed)
+
+	// Expected to be allowed
+	asrt.Admission = loader.AdmissionExpectation{Allowed: true}
+	assert.False(t, expectedAdmission(context.TODO(), prvMock, asrt).Passed)
+}
+
+func TestExpectedAdmissionNonAPIError(t *testing.T) {
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Unknown",
+			"metadata": map[string]interface{}{
+				"name": "unknown",
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("DryRun", context.TODO(), obj).Return(fmt.Errorf("no matches for kind \"Unknown\""))
+
+	asrt := loader.Assertion{
+		Name:    "denied",
+		Objects: []*unstructured.Unstructured{obj},
+	}
+
+	assert.False(t, expectedAdmission(context.TODO(), prvMock, asrt).Passed)
+}
+
+func TestExpectedAdmissionNoObjects(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	asrt := loader.Assertion{
+		Name:         "empty",
+		TestResource: "missing",
+	}
+
+	assert.False(t, expectedAdmission(context.TODO(), prvMock, asrt).Passed)
+	prvMock.AssertNumberOfCalls(t, "DryRun", 0)
+}
+
+func TestCountMatchesOperators(t *testing.T) {
+
+	asrt := loader.Assertion{Count: 3}
+
+	res, err := countMatches(asrt, 3)
+	assert.Nil(t, err)
+	assert.True(t, res)
+
+	asrt.Operator = "ge"
+	res, _ = countMatches(asrt, 5)
+	assert.True(t, res)
+	res, _ = countMatches(asrt, 2)
 	assert.False(t, res)
 
+	asrt.Operator = "lt"
+	res, _ = countMatches(asrt, 2)
+	assert.True(t, res)
+
+	asrt.Operator = "ne"
+	res, _ = countMatches(asrt, 3)
+	assert.False(t, res)
+
+	asrt.Operator = ">="
+	_, err = countMatches(asrt, 3)
+	assert.NotNil(t, err)
+}
+
+func TestCountMatchesMinMax(t *testing.T) {
+
+	min, max := 3, 5
+	asrt := loader.Assertion{Min: &min}
+
+	res, _ := countMatches(asrt, 10)
+	assert.True(t, res)
+	res, _ = countMatches(asrt, 2)
+	assert.False(t, res)
+
+	asrt.Max = &max
+	res, _ = countMatches(asrt, 10)
+	assert.False(t, res)
+	res, _ = countMatches(asrt, 4)
+	assert.True(t, res)
+}
+
+func TestCountMatchesAbsent(t *testing.T) {
+
+	asrt := loader.Assertion{Absent: true, Count: 1}
+
+	res, _ := countMatches(asrt, 0)
+	assert.True(t, res)
+	res, _ = countMatches(asrt, 1)
+	assert.False(t, res)
+}
+
+func TestExpectedResourcesAbsentWithErrors(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"namespace":  "default",
+		},
+		map[string]interface{}{
+			"status.phase": "Pending",
+		},
+	).Return(&unstructured.UnstructuredList{}, fmt.Errorf("the server is currently unable to handle the request"))
+
+	asrt := loader.Assertion{
+		Resource: "v1:Pod:default",
+		Selectors: map[string]interface{}{
+			"status.phase": "Pending",
+		},
+		Timeout: "4s",
+		Absent:  true,
+	}
+
+	// An empty list returned together with an error is not an absence
+	res := expectedResources(context.TODO(), prvMock, asrt)
+
+	assert.False(t, res.Passed)
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 2)
+}
+
+func TestExpectedResourcesStable(t *testing.T) {
+
+	retObjects := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{},
+				},
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Node",
+			"namespace":  "",
+		},
+		map[string]interface{}{},
+	).Return(retObjects, nil)
+
+	min := 1
+	asrt := loader.Assertion{
+		Resource:  "v1:Node",
+		Selectors: map[string]interface{}{},
+		Timeout:   "6s",
+		Min:       &min,
+		Stable:    "2s",
+	}
+
+	res := expectedResources(context.TODO(), prvMock, asrt)
+
+	assert.True(t, res.Passed)
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 2)
+}
+
+func TestExpectedResourcesWrongOperator(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	asrt := loader.Assertion{
+		Resource: "v1:Node",
+		Operator: "greater",
+	}
+
+	res := expectedResources(context.TODO(), prvMock, asrt)
+
+	assert.False(t, res.Passed)
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 0)
+}
+
+func TestExpectedExpression(t *testing.T) {
+
+	retObjects := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{"name": "limited", "namespace": "default"},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"resources": map[string]interface{}{
+									"limits":   map[string]interface{}{"memory": int64(256)},
+									"requests": map[string]interface{}{"memory": int64(128)},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{"name": "unlimited", "namespace": "default"},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"resources": map[string]interface{}{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"namespace":  "default",
+		},
+		map[string]interface{}{},
+	).Return(retObjects, nil)
+
+	asrt := loader.Assertion{
+		Name:       "limits",
+		Resource:   "v1:Pod:default",
+		Selectors:  map[string]interface{}{},
+		Timeout:    "2s",
+		Expression: "object.spec.containers.all(c, has(c.resources.limits) && c.resources.limits.memory >= c.resources.requests.memory)",
+	}
+
+	res := expectedExpression(context.TODO(), prvMock, asrt)
+
+	assert.False(t, res.Passed)
+	assert.Equal(t, "expression is false for: default/unlimited", res.Message)
+
+	asrt.Quantifier = "exists"
+	res = expectedExpression(context.TODO(), prvMock, asrt)
+
+	assert.True(t, res.Passed)
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 2)
+}
+
+func TestExpectedExpressionEmpty(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"namespace":  "default",
+		},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{}, nil)
+
+	asrt := loader.Assertion{
+		Resource:   "v1:Pod:default",
+		Selectors:  map[string]interface{}{},
+		Timeout:    "2s",
+		Expression: "has(object.spec)",
+	}
+
+	// all doesn't hold vacuously
+	res := expectedExpression(context.TODO(), prvMock, asrt)
+
+	assert.False(t, res.Passed)
+	assert.Equal(t, "no objects selected", res.Message)
+
+	asrt.AllowEmpty = true
+	res = expectedExpression(context.TODO(), prvMock, asrt)
+
+	assert.True(t, res.Passed)
+}
+
+func TestExpectedExpressionInvalid(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	asrt := loader.Assertion{
+		Resource:   "v1:Pod:default",
+		Expression: "object.spec.replicas >",
+	}
+
+	res := expectedExpression(context.TODO(), prvMock, asrt)
+
+	assert.False(t, res.Passed)
+	assert.Contains(t, res.Message, "invalid expression")
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 0)
+}
+
+func TestRunUnknownAssertionType(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	test := &loader.TestDefinition{
+		Assert: []loader.Assertion{
+			{Name: "unknown", Type: "expectedMagic"},
+		},
+	}
+
+	result, results := NewAssert(prvMock).Run(context.TODO(), test, nil)
+
+	assert.False(t, result)
+	assert.False(t, results["unknown"].Passed)
+	assert.Equal(t, "unknown assertion type 'expectedMagic'", results["unknown"].Message)
+}
+
+func TestSplitFieldPath(t *testing.T) {
+
+	assert.Equal(t, []string{"metadata", "managedFields"}, splitFieldPath("metadata.managedFields"))
+	assert.Equal(
+		t,
+		[]string{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"},
+		splitFieldPath("metadata.annotations.kubectl\\.kubernetes\\.io/last-applied-configuration"),
+	)
+}
+
+func TestExpectedState(t *testing.T) {
+
+	expected := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            "settings",
+				"namespace":       "default",
+				"resourceVersion": "1234",
+			},
+			"data": map[string]interface{}{
+				"feature": "enabled",
+			},
+		},
+	}
+	live := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            "settings",
+				"namespace":       "default",
+				"resourceVersion": "98765",
+				"uid":             "3f5b0e5a",
+			},
+			"data": map[string]interface{}{
+				"feature": "enabled",
+				"other":   "value",
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", context.TODO(), expected).Return(live, nil)
+
+	asrt := loader.Assertion{
+		Name:    "settings",
+		Timeout: "2s",
+		Objects: []*unstructured.Unstructured{expected},
+	}
+
+	res := expectedState(context.TODO(), prvMock, asrt)
+
+	assert.True(t, res.Passed)
+	prvMock.AssertNumberOfCalls(t, "Get", 1)
+}
+
+func TestExpectedStateDrift(t *testing.T) {
+
+	expected := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "nginx",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+			},
+			"status": map[string]interface{}{
+				"readyReplicas": int64(3),
+			},
+		},
+	}
+	live := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "nginx",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+			},
+			"status": map[string]interface{}{
+				"readyReplicas": int64(1),
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", context.TODO(), expected).Return(live, nil)
+
+	asrt := loader.Assertion{
+		Name:    "nginx",
+		Timeout: "2s",
+		Objects: []*unstructured.Unstructured{expected},
+	}
+
+	res := expectedState(context.TODO(), prvMock, asrt)
+
+	assert.False(t, res.Passed)
+	assert.Contains(t, res.Message, "--- expected apps/v1:Deployment:default:nginx")
+	assert.Contains(t, res.Message, "-  replicas: 3")
+	assert.Contains(t, res.Message, "+  replicas: 1")
+	assert.NotContains(t, res.Message, "readyReplicas")
+
+	// Status is only compared when requested
+	asrt.IncludeFields = []string{"status"}
+	res = expectedState(context.TODO(), prvMock, asrt)
+
+	assert.Contains(t, res.Message, "+  readyReplicas: 1")
+}
+
+func TestExpectedStateNotFound(t *testing.T) {
+
+	expected := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "settings",
+				"namespace": "default",
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", context.TODO(), expected).Return(
+		&unstructured.Unstructured{},
+		apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "settings"),
+	)
+
+	asrt := loader.Assertion{
+		Name:    "settings",
+		Timeout: "2s",
+		Objects: []*unstructured.Unstructured{expected},
+	}
+
+	res := expectedState(context.TODO(), prvMock, asrt)
+
+	assert.False(t, res.Passed)
+	assert.Contains(t, res.Message, "can't get v1:ConfigMap:default:settings")
+}
+
+func TestRunCompositeAssertions(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	setupErrors := []provisioner.ObjectError{
+		{Kind: "Pod", Name: "privileged", Reason: "Forbidden", Code: 403},
+	}
+	denied := loader.Assertion{
+		Name:        "denied",
+		Type:        "expectedErrors",
+		MatchErrors: []loader.ErrorMatcher{{Reason: "Forbidden"}},
+	}
+	conflict := loader.Assertion{
+		Name:        "conflict",
+		Type:        "expectedErrors",
+		MatchErrors: []loader.ErrorMatcher{{Reason: "AlreadyExists"}},
+	}
+
+	test := &loader.TestDefinition{
+		Assert: []loader.Assertion{
+			{Name: "all", Type: "allOf", Assertions: []loader.Assertion{denied, conflict}},
+			{Name: "any", Type: "anyOf", Assertions: []loader.Assertion{denied, conflict}},
+			{Name: "none", Type: "not", Assertions: []loader.Assertion{conflict}},
+		},
+	}
+
+	result, results := NewAssert(prvMock).Run(context.TODO(), test, setupErrors)
+
+	assert.False(t, result)
+	assert.False(t, results["all"].Passed)
+	assert.Equal(t, "failed sub-assertions: all.conflict", results["all"].Message)
+	assert.True(t, results["all.denied"].Passed)
+	assert.False(t, results["all.conflict"].Passed)
+	assert.True(t, results["any"].Passed)
+	assert.True(t, results["any.denied"].Passed)
+	assert.True(t, results["none"].Passed)
+	assert.False(t, results["none.conflict"].Passed)
+	assert.True(t, results["none.conflict"].Nested)
+	assert.False(t, results["none"].Nested)
+	assert.Len(t, results, 8)
+}
+
+func TestRunNestedCompositeAssertions(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	noErrors := loader.Assertion{Name: "no-errors", Type: "expectedErrors"}
+
+	test := &loader.TestDefinition{
+		Assert: []loader.Assertion{
+			{
+				Name: "outer",
+				Type: "not",
+				Assertions: []loader.Assertion{
+					{Name: "inner", Type: "anyOf", Assertions: []loader.Assertion{noErrors}},
+				},
+			},
+		},
+	}
+
+	result, results := NewAssert(prvMock).Run(context.TODO(), test, nil)
+
+	assert.False(t, result)
+	assert.Equal(t, "sub-assertion passed: outer.inner", results["outer"].Message)
+	assert.True(t, results["outer.inner.no-errors"].Passed)
+}
+
+func TestRunSeverities(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	setupErrors := []provisioner.ObjectError{{Reason: "Forbidden"}}
+
+	test := &loader.TestDefinition{
+		Assert: []loader.Assertion{
+			{Name: "no-errors", Type: "expectedErrors", Severity: loader.SeverityWarning},
+			{
+				Name:     "composite",
+				Type:     "allOf",
+				Severity: loader.SeverityInfo,
+				Assertions: []loader.Assertion{
+					{Name: "no-errors", Type: "expectedErrors"},
+				},
+			},
+		},
+	}
+
+	result, results := NewAssert(prvMock).Run(context.TODO(), test, setupErrors)
+
+	// Only critical failures fail the test
+	assert.True(t, result)
+	assert.False(t, results["no-errors"].Passed)
+	assert.Equal(t, loader.SeverityWarning, results["no-errors"].Severity)
+	assert.Equal(t, loader.SeverityInfo, results["composite.no-errors"].Severity)
+
+	test.Assert = append(test.Assert, loader.Assertion{Name: "critical", Type: "expectedErrors"})
+	result, results = NewAssert(prvMock).Run(context.TODO(), test, setupErrors)
+
+	assert.False(t, result)
+	assert.Equal(t, loader.SeverityCritical, results["critical"].Severity)
+}
+
+func TestRunPluginAssertion(t *testing.T) {
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat > /dev/null\necho 'service default/web has no load balancer' >&2\necho '{\"passed\": false, \"message\": \"not found\"}'\n"
+	err := os.WriteFile(filepath.Join(dir, "kubetest-loadBalancer"), []byte(script), 0755)
+	assert.Nil(t, err)
+
+	plugins, err := plugin.NewManager(dir, "", "")
+	assert.Nil(t, err)
+
+	asrt := NewAssert(new(provisioner.ProvisionerMock))
+	asrt.Plugins = plugins
+	test := &loader.TestDefinition{
+		Assert: []loader.Assertion{
+			{Name: "lb", Type: "loadBalancer", Params: map[string]interface{}{"service": "web"}},
+		},
+	}
+
+	result, results := asrt.Run(context.TODO(), test, nil)
+
+	assert.False(t, result)
+	assert.Equal(t, "not found\nstderr: service default/web has no load balancer", results["lb"].Message)
 }
@@ -2,36 +2,303 @@ package assert
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/ish-xyz/go-kubetest/pkg/expression"
+	"github.com/ish-xyz/go-kubetest/pkg/internal/helpers"
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
 )
 
-const defaultMaxWait = "60s"
+const defaultOperator = "eq"
 
-func getMaxRetries(waitTime string, interval int) int {
+const defaultQuantifier = "all"
 
-	// Get max wait time and retries/interval
-	maxWait, err := time.ParseDuration(waitTime)
-	if err != nil {
-		maxWait, _ = time.ParseDuration(defaultMaxWait)
+// Server-populated fields ignored by expectedState unless re-included
+var defaultIgnoredFields = []string{
+	"metadata.managedFields",
+	"metadata.resourceVersion",
+	"metadata.uid",
+	"metadata.generation",
+	"metadata.creationTimestamp",
+	"metadata.selfLink",
+	"metadata.annotations.kubectl\\.kubernetes\\.io/last-applied-configuration",
+	"status",
+}
+
+// Return the duration a condition has to hold before an assertion passes
+func getStableWindow(stable string) (time.Duration, error) {
+
+	if stable == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(stable)
+}
+
+// Compare the number of retrieved objects with the expectations of the assertion
+func countMatches(assertion loader.Assertion, count int) (bool, error) {
+
+	if assertion.Absent {
+		return count == 0, nil
+	}
+
+	if assertion.Min != nil || assertion.Max != nil {
+		if assertion.Min != nil && count < *assertion.Min {
+			return false, nil
+		}
+		if assertion.Max != nil && count > *assertion.Max {
+			return false, nil
+		}
+		return true, nil
+	}
+
+	operator := assertion.Operator
+	if operator == "" {
+		operator = defaultOperator
+	}
+
+	switch operator {
+	case "eq":
+		return count == assertion.Count, nil
+	case "ne":
+		return count != assertion.Count, nil
+	case "gt":
+		return count > assertion.Count, nil
+	case "ge":
+		return count >= assertion.Count, nil
+	case "lt":
+		return count < assertion.Count, nil
+	case "le":
+		return count <= assertion.Count, nil
+	}
+
+	return false, fmt.Errorf("unknown operator '%s'", operator)
+}
+
+// Check if a single object error satisfies the matcher, empty fields match anything
+func errorMatches(matcher loader.ErrorMatcher, objErr provisioner.ObjectError) bool {
+
+	if matcher.APIVersion != "" && matcher.APIVersion != objErr.APIVersion {
+		return false
+	}
+	if matcher.Kind != "" && matcher.Kind != objErr.Kind {
+		return false
+	}
+	if matcher.Namespace != "" && matcher.Namespace != objErr.Namespace {
+		return false
 	}
-	return int(maxWait.Seconds()) / interval
+	if matcher.Name != "" && matcher.Name != objErr.Name {
+		return false
+	}
+	if matcher.Code != 0 && matcher.Code != objErr.Code {
+		return false
+	}
+	if match, _ := regexp.MatchString(matcher.Reason, objErr.Reason); !match {
+		return false
+	}
+	match, _ := regexp.MatchString(matcher.Message, objErr.Message)
 
+	return match
 }
 
-func unpackResource(resourcePath string) (string, string, string, error) {
+// Check if every matcher can be paired with a different error (bipartite matching),
+// a greedy pairing isn't enough when a generic matcher steals the error of a specific one
+func matchAllErrors(matchers []loader.ErrorMatcher, objErrors []provisioner.ObjectError) bool {
 
-	path := strings.TrimSuffix(strings.TrimPrefix(resourcePath, ":"), ":")
-	nvk := strings.Split(path, ":")
+	// pairedWith[errorIndex] = matcherIndex
+	pairedWith := make([]int, len(objErrors))
+	for index := range pairedWith {
+		pairedWith[index] = -1
+	}
+
+	var tryPair func(matcherIndex int, visited []bool) bool
+	tryPair = func(matcherIndex int, visited []bool) bool {
+		for errIndex, objErr := range objErrors {
+			if visited[errIndex] || !errorMatches(matchers[matcherIndex], objErr) {
+				continue
+			}
+			visited[errIndex] = true
+			if pairedWith[errIndex] == -1 || tryPair(pairedWith[errIndex], visited) {
+				pairedWith[errIndex] = matcherIndex
+				return true
+			}
+		}
+		return false
+	}
+
+	for matcherIndex := range matchers {
+		if !tryPair(matcherIndex, make([]bool, len(objErrors))) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate the expression against each object, failing objects are reported in the message,
+// no selected object only satisfies the all quantifier with allowEmpty
+func evalExpression(prg cel.Program, quantifier string, allowEmpty bool, items []unstructured.Unstructured) Result {
+
+	if quantifier == "" {
+		quantifier = defaultQuantifier
+	}
 
-	if len(nvk) == 2 {
-		return nvk[0], nvk[1], "", nil
+	objects := make([]interface{}, len(items))
+	for index := range items {
+		objects[index] = items[index].Object
 	}
 
-	if len(nvk) == 3 {
-		return nvk[0], nvk[1], nvk[2], nil
+	var failing []string
+	for _, item := range items {
+		passed, err := expression.Eval(prg, item.Object, objects)
+		if err != nil {
+			failing = append(failing, fmt.Sprintf("%s (%v)", objectName(item), err))
+			continue
+		}
+		if !passed {
+			failing = append(failing, objectName(item))
+		}
 	}
 
-	return "", "", "", fmt.Errorf("can't unpack resource path, wrong syntax")
+	switch quantifier {
+	case "all":
+		if len(items) == 0 && !allowEmpty {
+			return Result{Message: "no objects selected"}
+		}
+		if len(failing) == 0 {
+			return Result{Passed: true}
+		}
+		return Result{Message: fmt.Sprintf("expression is false for: %s", strings.Join(failing, ", "))}
+	case "exists":
+		if len(failing) < len(items) {
+			return Result{Passed: true}
+		}
+		if len(items) == 0 {
+			return Result{Message: "no objects selected"}
+		}
+		return Result{Message: fmt.Sprintf("expression is false for every object: %s", strings.Join(failing, ", "))}
+	}
+
+	return Result{Message: fmt.Sprintf("unknown quantifier '%s'", quantifier)}
+}
+
+func objectName(obj unstructured.Unstructured) string {
+
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName())
+}
+
+// Return the fields ignored by expectedState, as lists of path segments
+func getIgnoredFields(ignoreFields, includeFields []string) [][]string {
+
+	var ignored [][]string
+	included := map[string]bool{}
+	for _, field := range includeFields {
+		included[field] = true
+	}
+
+	for _, field := range append(defaultIgnoredFields, ignoreFields...) {
+		if included[field] {
+			continue
+		}
+		ignored = append(ignored, splitFieldPath(field))
+	}
+	return ignored
+}
+
+// Split a dotted path, dots escaped with a backslash are part of the key
+func splitFieldPath(path string) []string {
+
+	var segments []string
+	var current strings.Builder
+
+	for index := 0; index < len(path); index++ {
+		switch {
+		case path[index] == '\\' && index+1 < len(path) && path[index+1] == '.':
+			current.WriteByte('.')
+			index++
+		case path[index] == '.':
+			segments = append(segments, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(path[index])
+		}
+	}
+	return append(segments, current.String())
+}
+
+// Compare the expected manifest with the live object, an empty diff means
+// every field of the expected manifest has the same value on the live object
+func stateDiff(expected, live *unstructured.Unstructured, ignored [][]string) (string, error) {
+
+	exp := expected.DeepCopy()
+	for _, field := range ignored {
+		unstructured.RemoveNestedField(exp.Object, field...)
+	}
+
+	// Only keep the live fields that are part of the expected manifest
+	projection := projectFields(exp.Object, live.Object)
+	if reflect.DeepEqual(exp.Object, projection) {
+		return "", nil
+	}
+
+	expYAML, err := yaml.Marshal(exp.Object)
+	if err != nil {
+		return "", err
+	}
+	liveYAML, err := yaml.Marshal(projection)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expYAML)),
+		B:        difflib.SplitLines(string(liveYAML)),
+		FromFile: fmt.Sprintf("expected %s", helpers.ObjectPath(expected)),
+		ToFile:   fmt.Sprintf("live %s", helpers.ObjectPath(expected)),
+		Context:  3,
+	})
+}
+
+// Return the subset of live that has the same shape as expected,
+// list items are compared by position and extra live items are kept
+func projectFields(expected, live interface{}) interface{} {
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		projection := map[string]interface{}{}
+		for key := range exp {
+			if value, found := liveMap[key]; found {
+				projection[key] = projectFields(exp[key], value)
+			}
+		}
+		return projection
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		projection := make([]interface{}, len(liveList))
+		for index, value := range liveList {
+			projection[index] = value
+			if index < len(exp) {
+				projection[index] = projectFields(exp[index], value)
+			}
+		}
+		return projection
+	}
 
+	return live
 }
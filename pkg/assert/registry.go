@@ -0,0 +1,74 @@
+package assert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+)
+
+// Return an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		checkers: map[string]Checker{},
+	}
+}
+
+// Register the checker of an assertion type, registered types can't be replaced
+func (r *Registry) Register(assertionType string, checker Checker) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if assertionType == "" || checker == nil {
+		return fmt.Errorf("assertion type and checker are required")
+	}
+	if _, ok := r.checkers[assertionType]; ok {
+		return fmt.Errorf("assertion type '%s' is already registered", assertionType)
+	}
+
+	r.checkers[assertionType] = checker
+	return nil
+}
+
+// Return the checker registered for an assertion type
+func (r *Registry) Get(assertionType string) (Checker, bool) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checker, ok := r.checkers[assertionType]
+	return checker, ok
+}
+
+// Return the registered assertion types, sorted by name
+func (r *Registry) Types() []string {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.checkers))
+	for assertionType := range r.checkers {
+		types = append(types, assertionType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (f CheckerFunc) Check(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+	return f(ctx, prv, assertion)
+}
+
+// Return a copy of ctx carrying the errors returned during setup
+func WithSetupErrors(ctx context.Context, errors []provisioner.ObjectError) context.Context {
+	return context.WithValue(ctx, setupErrorsKey, errors)
+}
+
+// Return the errors returned during setup, available to checkers run by Assert.Run
+func SetupErrors(ctx context.Context) []provisioner.ObjectError {
+
+	errors, _ := ctx.Value(setupErrorsKey).([]provisioner.ObjectError)
+	return errors
+}
@@ -0,0 +1,103 @@
+package assert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryRegister(t *testing.T) {
+
+	registry := NewRegistry()
+	checker := CheckerFunc(func(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+		return Result{Passed: true}
+	})
+
+	assert.Nil(t, registry.Register("loadBalancer", checker))
+	assert.NotNil(t, registry.Register("loadBalancer", checker))
+	assert.NotNil(t, registry.Register("", checker))
+	assert.NotNil(t, registry.Register("nilChecker", nil))
+
+	_, ok := registry.Get("loadBalancer")
+	assert.True(t, ok)
+	_, ok = registry.Get("unknown")
+	assert.False(t, ok)
+}
+
+func TestBuiltinTypes(t *testing.T) {
+
+	asrt := NewAssert(new(provisioner.ProvisionerMock))
+
+	assert.Equal(
+		t,
+		[]string{
+			"allOf",
+			"anyOf",
+			"expectedAdmission",
+			"expectedErrors",
+			"expectedExpression",
+			"expectedResources",
+			"expectedState",
+			"not",
+		},
+		asrt.Registry.Types(),
+	)
+
+	// Built-ins can't be replaced
+	err := asrt.Registry.Register("expectedErrors", CheckerFunc(expectedErrors))
+	assert.NotNil(t, err)
+}
+
+func TestRunCustomChecker(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	asrt := NewAssert(prvMock)
+
+	var received loader.Assertion
+	var setupErrors []provisioner.ObjectError
+	err := asrt.Registry.Register("featureFlag", CheckerFunc(
+		func(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result {
+			received = assertion
+			setupErrors = SetupErrors(ctx)
+			return Result{Message: "flag disabled"}
+		},
+	))
+	assert.Nil(t, err)
+
+	test := &loader.TestDefinition{
+		Assert: []loader.Assertion{
+			{
+				Name: "composite",
+				Type: "allOf",
+				Assertions: []loader.Assertion{
+					{Name: "flag", Type: "featureFlag", Params: map[string]interface{}{"flag": "new-ui"}},
+				},
+			},
+		},
+	}
+
+	result, results := asrt.Run(context.TODO(), test, []provisioner.ObjectError{{Name: "settings"}})
+
+	assert.False(t, result)
+	assert.Equal(t, "new-ui", received.Params["flag"])
+	assert.Equal(t, "settings", setupErrors[0].Name)
+	assert.Equal(t, "flag disabled", results["composite.flag"].Message)
+	assert.Equal(t, loader.SeverityCritical, results["composite.flag"].Severity)
+}
+
+func TestCompositeOutsideRun(t *testing.T) {
+
+	asrt := NewAssert(new(provisioner.ProvisionerMock))
+	checker, _ := asrt.Registry.Get("anyOf")
+
+	res := checker.Check(context.TODO(), asrt.Provisioner, loader.Assertion{
+		Name:       "any",
+		Type:       "anyOf",
+		Assertions: []loader.Assertion{{Name: "no-errors", Type: "expectedErrors"}},
+	})
+
+	assert.True(t, res.Passed)
+}
@@ -1,9 +1,57 @@
 package assert
 
 import (
+	"context"
+	"sync"
+
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/plugin"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 )
 
 type Assert struct {
 	Provisioner provisioner.Provisioner
+	// Checkers of the in-process assertion types, built-ins included
+	Registry *Registry
+	// Optional, handles the assertion types unknown to the registry
+	Plugins *plugin.Manager
+}
+
+// Result of a single assertion, message explains why it failed
+type Result struct {
+	Passed   bool
+	Message  string
+	Severity string
+	// Result of a sub-assertion, only its composite assertion counts
+	Nested bool
+}
+
+// Interfaces
+
+// Checker evaluates the assertions of a given type
+type Checker interface {
+	Check(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result
+}
+
+// CheckerFunc allows the use of ordinary functions as checkers
+type CheckerFunc func(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) Result
+
+// Registry maps assertion types to their checkers
+type Registry struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+}
+
+type contextKey int
+
+const (
+	setupErrorsKey contextKey = iota
+	runStateKey
+)
+
+// Where a composite assertion records the results of its sub-assertions
+type runState struct {
+	key      string
+	severity string
+	results  map[string]Result
 }
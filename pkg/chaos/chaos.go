@@ -0,0 +1,358 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ish-xyz/go-kubetest/pkg/internal/helpers"
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Return a new chaos runner, an empty namespaces list disables the pod and deployment actions
+func NewRunner(prv provisioner.Provisioner, namespaces []string, maxAffected int, allowNodes bool) *Runner {
+
+	return &Runner{
+		Provisioner: prv,
+		Namespaces:  namespaces,
+		MaxAffected: maxAffected,
+		AllowNodes:  allowNodes,
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Run a chaos action, the returned restore function is nil if there's nothing to undo
+func (r *Runner) Run(ctx context.Context, action loader.ChaosAction) (Restore, error) {
+
+	logrus.Debugf("Running chaos action '%s' (%s)", action.Name, action.Type)
+
+	switch action.Type {
+	case "deletePods":
+		return nil, r.deletePods(ctx, action)
+	case "evictPods":
+		return nil, r.evictPods(ctx, action)
+	case "cordonNode":
+		return r.cordonNode(ctx, action)
+	case "drainNode":
+		return r.drainNode(ctx, action)
+	case "scaleDeployment":
+		return r.scaleDeployment(ctx, action)
+	}
+
+	return nil, fmt.Errorf("unknown chaos action '%s'", action.Type)
+}
+
+// Delete random pods matching the selectors
+func (r *Runner) deletePods(ctx context.Context, action loader.ChaosAction) error {
+
+	pods, err := r.pickPods(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		if err := r.Provisioner.Delete(ctx, pod); err != nil {
+			return fmt.Errorf("can't delete pod %s: %v", pod.GetName(), err)
+		}
+		logrus.Debugf("Chaos: pod deleted %s/%s", pod.GetNamespace(), pod.GetName())
+	}
+	return nil
+}
+
+// Evict random pods matching the selectors, evictions blocked by a PodDisruptionBudget
+// are retried until the action times out
+func (r *Runner) evictPods(ctx context.Context, action loader.ChaosAction) error {
+
+	pods, err := r.pickPods(ctx, action)
+	if err != nil {
+		return err
+	}
+	return r.evict(ctx, pods, action.Timeout)
+}
+
+// Mark a node as unschedulable, restored by uncordoning it
+func (r *Runner) cordonNode(ctx context.Context, action loader.ChaosAction) (Restore, error) {
+
+	node, err := r.pickNode(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+	return r.cordon(ctx, node)
+}
+
+// Cordon a node and evict its pods, DaemonSet or mirror pods are left alone.
+// The drain is refused if the node runs evictable pods outside of the allowed namespaces,
+// restored by uncordoning the node
+func (r *Runner) drainNode(ctx context.Context, action loader.ChaosAction) (Restore, error) {
+
+	node, err := r.pickNode(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := r.Provisioner.ListWithSelectors(
+		ctx,
+		map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"namespace":  "",
+		},
+		map[string]interface{}{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []*unstructured.Unstructured
+	var outside []string
+	for _, namespace := range namespaces.Items {
+		list, err := r.Provisioner.ListWithSelectors(
+			ctx,
+			map[string]string{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"namespace":  namespace.GetName(),
+			},
+			map[string]interface{}{
+				"spec.nodeName": node.GetName(),
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		for index := range list.Items {
+			pod := &list.Items[index]
+			if !evictable(pod) {
+				continue
+			}
+			if !r.namespaceAllowed(pod.GetNamespace()) {
+				outside = append(outside, fmt.Sprintf("%s/%s", pod.GetNamespace(), pod.GetName()))
+				continue
+			}
+			pods = append(pods, pod)
+		}
+	}
+	if len(outside) > 0 {
+		return nil, fmt.Errorf("draining node %s would evict pods outside of the allowed namespaces: %s", node.GetName(), strings.Join(outside, ", "))
+	}
+	if len(pods) > r.MaxAffected {
+		return nil, fmt.Errorf("draining node %s would evict %d pods, max affected is %d", node.GetName(), len(pods), r.MaxAffected)
+	}
+
+	restore, err := r.cordon(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	return restore, r.evict(ctx, pods, action.Timeout)
+}
+
+// Scale a deployment, restored by scaling it back to the original replicas
+func (r *Runner) scaleDeployment(ctx context.Context, action loader.ChaosAction) (Restore, error) {
+
+	if !r.namespaceAllowed(action.Namespace) {
+		return nil, fmt.Errorf("namespace %s is not allowed for chaos actions", action.Namespace)
+	}
+	if r.MaxAffected < 1 {
+		return nil, fmt.Errorf("max affected is %d", r.MaxAffected)
+	}
+
+	deployment, err := r.Provisioner.Get(ctx, newObject("apps/v1", "Deployment", action.Namespace, action.Target))
+	if err != nil {
+		return nil, err
+	}
+
+	// Replicas default to 1 when not set
+	original, found, err := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		original = 1
+	}
+
+	if err := r.scale(ctx, deployment, int64(action.Replicas)); err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Chaos: deployment %s/%s scaled from %d to %d", action.Namespace, action.Target, original, action.Replicas)
+
+	return func(ctx context.Context) error {
+		return r.scale(ctx, deployment, original)
+	}, nil
+}
+
+// Return random pods matching the selectors, pods already being deleted are excluded
+func (r *Runner) pickPods(ctx context.Context, action loader.ChaosAction) ([]*unstructured.Unstructured, error) {
+
+	if !r.namespaceAllowed(action.Namespace) {
+		return nil, fmt.Errorf("namespace %s is not allowed for chaos actions", action.Namespace)
+	}
+
+	count := action.Count
+	if count == 0 {
+		count = 1
+	}
+	if count > r.MaxAffected {
+		return nil, fmt.Errorf("action would affect %d pods, max affected is %d", count, r.MaxAffected)
+	}
+
+	list, err := r.Provisioner.ListWithSelectors(
+		ctx,
+		map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"namespace":  action.Namespace,
+		},
+		action.Selectors,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []*unstructured.Unstructured
+	for index := range list.Items {
+		if list.Items[index].GetDeletionTimestamp() == nil {
+			pods = append(pods, &list.Items[index])
+		}
+	}
+	if len(pods) == 0 {
+		return nil, errors.New("no pods matching the selectors")
+	}
+
+	r.mu.Lock()
+	r.random.Shuffle(len(pods), func(i, j int) { pods[i], pods[j] = pods[j], pods[i] })
+	r.mu.Unlock()
+	if count < len(pods) {
+		pods = pods[:count]
+	}
+	return pods, nil
+}
+
+// Return the target node or a random node matching the selectors
+func (r *Runner) pickNode(ctx context.Context, action loader.ChaosAction) (*unstructured.Unstructured, error) {
+
+	if !r.AllowNodes {
+		return nil, errors.New("node actions are not allowed")
+	}
+	if r.MaxAffected < 1 {
+		return nil, fmt.Errorf("max affected is %d", r.MaxAffected)
+	}
+
+	if action.Target != "" {
+		return r.Provisioner.Get(ctx, newObject("v1", "Node", "", action.Target))
+	}
+
+	list, err := r.Provisioner.ListWithSelectors(
+		ctx,
+		map[string]string{
+			"apiVersion": "v1",
+			"kind":       "Node",
+			"namespace":  "",
+		},
+		action.Selectors,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, errors.New("no nodes matching the selectors")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &list.Items[r.random.Intn(len(list.Items))], nil
+}
+
+// Cordon a node, nodes that are already unschedulable are left as they are
+func (r *Runner) cordon(ctx context.Context, node *unstructured.Unstructured) (Restore, error) {
+
+	unschedulable, _, _ := unstructured.NestedBool(node.Object, "spec", "unschedulable")
+	if unschedulable {
+		logrus.Debugf("Chaos: node %s is already cordoned", node.GetName())
+		return nil, nil
+	}
+
+	err := r.Provisioner.Patch(ctx, node, types.MergePatchType, []byte(`{"spec":{"unschedulable":true}}`))
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Chaos: node cordoned %s", node.GetName())
+
+	return func(ctx context.Context) error {
+		return r.Provisioner.Patch(ctx, node, types.MergePatchType, []byte(`{"spec":{"unschedulable":false}}`))
+	}, nil
+}
+
+// Evict the pods, retrying the evictions refused because of a PodDisruptionBudget
+func (r *Runner) evict(ctx context.Context, pods []*unstructured.Unstructured, timeout string) error {
+
+	// At least one attempt, even with a timeout shorter than the interval
+	limit := helpers.MaxRetries(timeout, evictionInterval)
+	if limit < 1 {
+		limit = 1
+	}
+
+	for _, pod := range pods {
+		var err error
+		for counter := 0; counter < limit; counter++ {
+			err = r.Provisioner.Evict(ctx, pod)
+			if !apierrors.IsTooManyRequests(err) {
+				break
+			}
+			logrus.Debugf("Chaos: eviction of pod %s/%s blocked by a disruption budget, retrying", pod.GetNamespace(), pod.GetName())
+			if !helpers.Sleep(ctx, evictionInterval) {
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("can't evict pod %s: %v", pod.GetName(), err)
+		}
+		logrus.Debugf("Chaos: pod evicted %s/%s", pod.GetNamespace(), pod.GetName())
+	}
+	return nil
+}
+
+func (r *Runner) scale(ctx context.Context, deployment *unstructured.Unstructured, replicas int64) error {
+	return r.Provisioner.Patch(ctx, deployment, types.MergePatchType, []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)))
+}
+
+func (r *Runner) namespaceAllowed(namespace string) bool {
+
+	for _, allowed := range r.Namespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// DaemonSet pods would be recreated on the node and mirror pods can't be evicted
+func evictable(pod *unstructured.Unstructured) bool {
+
+	if _, ok := pod.GetAnnotations()[mirrorPodAnnotation]; ok {
+		return false
+	}
+	for _, owner := range pod.GetOwnerReferences() {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+func newObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
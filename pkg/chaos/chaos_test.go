@@ -0,0 +1,227 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var ctxTest = context.TODO()
+
+func newPod(name string, ownerKind string) unstructured.Unstructured {
+
+	pod := newObject("v1", "Pod", "app", name)
+	if ownerKind != "" {
+		pod.Object["metadata"].(map[string]interface{})["ownerReferences"] = []interface{}{
+			map[string]interface{}{"apiVersion": "apps/v1", "kind": ownerKind, "name": "owner", "uid": "1"},
+		}
+	}
+	return *pod
+}
+
+func TestDeletePods(t *testing.T) {
+
+	// Prepare test data & mock
+	pods := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{newPod("app-1", ""), newPod("app-2", ""), newPod("app-3", "")},
+	}
+	selectors := map[string]interface{}{"metadata.labels.app": "echo"}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "app"},
+		selectors,
+	).Return(pods, nil)
+	prvMock.On("Delete", ctxTest, mock.Anything).Return(nil)
+
+	// Run tests
+	runner := NewRunner(prvMock, []string{"app"}, 2, false)
+	restore, err := runner.Run(ctxTest, loader.ChaosAction{
+		Name:      "kill-two",
+		Type:      "deletePods",
+		Namespace: "app",
+		Selectors: selectors,
+		Count:     2,
+	})
+
+	assert.Nil(t, err)
+	assert.Nil(t, restore)
+	prvMock.AssertNumberOfCalls(t, "Delete", 2)
+}
+
+func TestGuards(t *testing.T) {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	runner := NewRunner(prvMock, []string{"app"}, 1, false)
+
+	// Namespace not in the allowlist
+	_, err := runner.Run(ctxTest, loader.ChaosAction{Name: "kill", Type: "deletePods", Namespace: "kube-system"})
+	assert.NotNil(t, err)
+
+	// More pods than max affected
+	_, err = runner.Run(ctxTest, loader.ChaosAction{Name: "kill", Type: "evictPods", Namespace: "app", Count: 2})
+	assert.NotNil(t, err)
+
+	// Node actions not allowed
+	_, err = runner.Run(ctxTest, loader.ChaosAction{Name: "cordon", Type: "cordonNode", Target: "node-1"})
+	assert.NotNil(t, err)
+
+	_, err = runner.Run(ctxTest, loader.ChaosAction{Name: "scale", Type: "scaleDeployment", Namespace: "default", Target: "echo"})
+	assert.NotNil(t, err)
+
+	// Nothing has been touched
+	assert.Empty(t, prvMock.Calls)
+}
+
+func TestScaleDeployment(t *testing.T) {
+
+	// Prepare test data & mock
+	deployment := newObject("apps/v1", "Deployment", "app", "echo")
+	unstructured.SetNestedField(deployment.Object, int64(3), "spec", "replicas")
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", ctxTest, newObject("apps/v1", "Deployment", "app", "echo")).Return(deployment, nil)
+	prvMock.On("Patch", ctxTest, deployment, types.MergePatchType, []byte(`{"spec":{"replicas":0}}`)).Return(nil)
+	prvMock.On("Patch", ctxTest, deployment, types.MergePatchType, []byte(`{"spec":{"replicas":3}}`)).Return(nil)
+
+	// Run tests
+	runner := NewRunner(prvMock, []string{"app"}, 1, false)
+	restore, err := runner.Run(ctxTest, loader.ChaosAction{
+		Name:      "scale-to-zero",
+		Type:      "scaleDeployment",
+		Namespace: "app",
+		Target:    "echo",
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, restore)
+	prvMock.AssertCalled(t, "Patch", ctxTest, deployment, types.MergePatchType, []byte(`{"spec":{"replicas":0}}`))
+
+	assert.Nil(t, restore(ctxTest))
+	prvMock.AssertCalled(t, "Patch", ctxTest, deployment, types.MergePatchType, []byte(`{"spec":{"replicas":3}}`))
+}
+
+func TestDrainNode(t *testing.T) {
+
+	// Prepare test data & mock
+	node := newObject("v1", "Node", "", "node-1")
+	pods := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{newPod("app-1", "ReplicaSet"), newPod("agent-1", "DaemonSet")},
+	}
+	systemPod := newPod("proxy-1", "DaemonSet")
+	systemPod.SetNamespace("kube-system")
+	tooManyRequests := apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", ctxTest, node).Return(node, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Namespace", "namespace": ""},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{*newObject("v1", "Namespace", "", "app"), *newObject("v1", "Namespace", "", "kube-system")},
+	}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "app"},
+		map[string]interface{}{"spec.nodeName": "node-1"},
+	).Return(pods, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "kube-system"},
+		map[string]interface{}{"spec.nodeName": "node-1"},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{systemPod}}, nil)
+	prvMock.On("Patch", ctxTest, node, types.MergePatchType, mock.Anything).Return(nil)
+	prvMock.On("Evict", ctxTest, &pods.Items[0]).Return(tooManyRequests).Once()
+	prvMock.On("Evict", ctxTest, &pods.Items[0]).Return(nil).Once()
+
+	// Run tests
+	runner := NewRunner(prvMock, []string{"app"}, 1, true)
+	restore, err := runner.Run(ctxTest, loader.ChaosAction{
+		Name:    "drain",
+		Type:    "drainNode",
+		Target:  "node-1",
+		Timeout: "10s",
+	})
+
+	// The DaemonSet pods are left alone, the eviction blocked by the budget is retried
+	assert.Nil(t, err)
+	prvMock.AssertNumberOfCalls(t, "Evict", 2)
+	prvMock.AssertCalled(t, "Patch", ctxTest, node, types.MergePatchType, []byte(`{"spec":{"unschedulable":true}}`))
+
+	assert.Nil(t, restore(ctxTest))
+	prvMock.AssertCalled(t, "Patch", ctxTest, node, types.MergePatchType, []byte(`{"spec":{"unschedulable":false}}`))
+}
+
+func TestDrainNodePodsOutsideNamespaces(t *testing.T) {
+
+	// Prepare test data & mock, the node runs a pod of a namespace that isn't allowed
+	node := newObject("v1", "Node", "", "node-1")
+	otherPod := newPod("billing-1", "ReplicaSet")
+	otherPod.SetNamespace("billing")
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", ctxTest, node).Return(node, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Namespace", "namespace": ""},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{*newObject("v1", "Namespace", "", "app"), *newObject("v1", "Namespace", "", "billing")},
+	}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "app"},
+		map[string]interface{}{"spec.nodeName": "node-1"},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{newPod("app-1", "ReplicaSet")}}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "billing"},
+		map[string]interface{}{"spec.nodeName": "node-1"},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{otherPod}}, nil)
+
+	// Run tests
+	runner := NewRunner(prvMock, []string{"app"}, 5, true)
+	restore, err := runner.Run(ctxTest, loader.ChaosAction{Name: "drain", Type: "drainNode", Target: "node-1"})
+
+	// The drain is refused before cordoning the node
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "billing/billing-1")
+	assert.Nil(t, restore)
+	prvMock.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	prvMock.AssertNotCalled(t, "Evict", mock.Anything, mock.Anything)
+}
+
+func TestCordonNodeAlreadyCordoned(t *testing.T) {
+
+	// Prepare test data & mock
+	node := newObject("v1", "Node", "", "node-1")
+	unstructured.SetNestedField(node.Object, true, "spec", "unschedulable")
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", ctxTest, newObject("v1", "Node", "", "node-1")).Return(node, nil)
+
+	// Run tests
+	runner := NewRunner(prvMock, nil, 1, true)
+	restore, err := runner.Run(ctxTest, loader.ChaosAction{Name: "cordon", Type: "cordonNode", Target: "node-1"})
+
+	// The node is not uncordoned by teardown
+	assert.Nil(t, err)
+	assert.Nil(t, restore)
+	prvMock.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
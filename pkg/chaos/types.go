@@ -0,0 +1,33 @@
+package chaos
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+)
+
+// Seconds between two evictions attempts blocked by a PodDisruptionBudget
+const evictionInterval = 2
+
+// Annotation set on the static pods mirrored by the kubelet, they can't be evicted
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// Restore undoes an action (e.g. uncordon a node), run by teardown
+type Restore func(ctx context.Context) error
+
+// Runner runs the chaos actions, refusing the ones outside of its guards
+type Runner struct {
+	Provisioner provisioner.Provisioner
+	// Namespaces where pods can be deleted or evicted and deployments scaled
+	Namespaces []string
+	// Max number of objects (pods, nodes or deployments) affected by a single action
+	MaxAffected int
+	// Allow the node actions: cordonNode, drainNode
+	AllowNodes bool
+
+	// Tests run in parallel share the runner
+	mu     sync.Mutex
+	random *rand.Rand
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestResult(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResult",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"spec": spec,
+		},
+	}
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		v1.SchemeGroupVersion.WithResource(v1.TestResultResource): "TestResultList",
+	}, objects...)
+}
+
+func TestFromUnstructured(t *testing.T) {
+
+	result := &v1.TestResult{}
+	err := FromUnstructured(newTestResult("test-1", map[string]interface{}{
+		"result":     true,
+		"assertions": map[string]interface{}{"limits": true},
+		"flakiness":  int64(0),
+	}), result)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test-1", result.Name)
+	assert.True(t, result.Spec.Result)
+	assert.Equal(t, map[string]bool{"limits": true}, result.Spec.Assertions)
+}
+
+func TestFromUnstructuredInvalid(t *testing.T) {
+
+	err := FromUnstructured(newTestResult("test-1", map[string]interface{}{
+		"result": "yes",
+	}), &v1.TestResult{})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid TestResult default/test-1")
+}
+
+func TestToUnstructured(t *testing.T) {
+
+	obj, err := ToUnstructured(&v1.TestResource{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "go-kubetest.io/v1", Kind: "TestResource"},
+		ObjectMeta: metav1.ObjectMeta{Name: "manifests"},
+		Spec:       v1.TestResourceSpec{Data: "kind: Namespace"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "TestResource", obj.GetKind())
+	assert.Equal(t, "kind: Namespace", obj.Object["spec"].(map[string]interface{})["data"])
+}
+
+func TestTestResultInformer(t *testing.T) {
+
+	// A TestResult that can't be converted doesn't prevent the others from being listed
+	dc := newFakeDynamicClient(
+		newTestResult("broken", map[string]interface{}{"result": "yes"}),
+		newTestResult("test-1", map[string]interface{}{"result": true}),
+	)
+
+	added := make(chan *v1.TestResult, 2)
+	informer := NewTestResultInformer(dc, "default", 0)
+	informer.AddEventHandler(TestResultHandlerFuncs{
+		AddFunc: func(obj *v1.TestResult) {
+			added <- obj
+		},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go informer.Run(stopCh)
+
+	select {
+	case obj := <-added:
+		assert.Equal(t, "test-1", obj.Name)
+		assert.True(t, obj.Spec.Result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the informer didn't receive the TestResult")
+	}
+
+	assert.True(t, cache.WaitForCacheSync(stopCh, informer.Informer.HasSynced))
+	assert.Len(t, added, 0)
+}
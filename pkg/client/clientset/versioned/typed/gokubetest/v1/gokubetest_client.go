@@ -0,0 +1,106 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"net/http"
+
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"github.com/ish-xyz/go-kubetest/pkg/client/clientset/versioned/scheme"
+	rest "k8s.io/client-go/rest"
+)
+
+type KubetestV1Interface interface {
+	RESTClient() rest.Interface
+	TestDefinitionsGetter
+	TestFixturesGetter
+	TestResourcesGetter
+	TestResultsGetter
+}
+
+// KubetestV1Client is used to interact with features provided by the go-kubetest.io group.
+type KubetestV1Client struct {
+	restClient rest.Interface
+}
+
+func (c *KubetestV1Client) TestDefinitions(namespace string) TestDefinitionInterface {
+	return newTestDefinitions(c, namespace)
+}
+
+func (c *KubetestV1Client) TestFixtures(namespace string) TestFixtureInterface {
+	return newTestFixtures(c, namespace)
+}
+
+func (c *KubetestV1Client) TestResources(namespace string) TestResourceInterface {
+	return newTestResources(c, namespace)
+}
+
+func (c *KubetestV1Client) TestResults(namespace string) TestResultInterface {
+	return newTestResults(c, namespace)
+}
+
+// NewForConfig creates a new KubetestV1Client for the given config.
+// NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
+// where httpClient was generated with rest.HTTPClientFor(c).
+func NewForConfig(c *rest.Config) (*KubetestV1Client, error) {
+	config := *c
+	if err := setConfigDefaults(&config); err != nil {
+		return nil, err
+	}
+	httpClient, err := rest.HTTPClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+	return NewForConfigAndClient(&config, httpClient)
+}
+
+// NewForConfigAndClient creates a new KubetestV1Client for the given config and http client.
+// Note the http client provided takes precedence over the configured transport values.
+func NewForConfigAndClient(c *rest.Config, h *http.Client) (*KubetestV1Client, error) {
+	config := *c
+	if err := setConfigDefaults(&config); err != nil {
+		return nil, err
+	}
+	client, err := rest.RESTClientForConfigAndClient(&config, h)
+	if err != nil {
+		return nil, err
+	}
+	return &KubetestV1Client{client}, nil
+}
+
+// NewForConfigOrDie creates a new KubetestV1Client for the given config and
+// panics if there is an error in the config.
+func NewForConfigOrDie(c *rest.Config) *KubetestV1Client {
+	client, err := NewForConfig(c)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// New creates a new KubetestV1Client for the given RESTClient.
+func New(c rest.Interface) *KubetestV1Client {
+	return &KubetestV1Client{c}
+}
+
+func setConfigDefaults(config *rest.Config) error {
+	gv := v1.SchemeGroupVersion
+	config.GroupVersion = &gv
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	return nil
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *KubetestV1Client) RESTClient() rest.Interface {
+	if c == nil {
+		return nil
+	}
+	return c.restClient
+}
@@ -0,0 +1,6 @@
+// Package client contains the clients of the go-kubetest.io resources.
+//
+// The clientset, listers and informers in the subpackages are generated by hack/update-codegen.sh.
+// The TestResult informer of this package works on unstructured objects and converts them one by one,
+// so that a TestResult that can't be decoded is skipped instead of failing the whole list.
+package client
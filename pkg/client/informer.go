@@ -0,0 +1,101 @@
+package client
+
+import (
+	"time"
+
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Return an informer of the TestResults of a namespace, empty for all namespaces.
+// TestResults are listed and watched as unstructured objects, the ones that can't be converted are logged and skipped
+func NewTestResultInformer(dc dynamic.Interface, namespace string, resync time.Duration) *TestResultInformer {
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(
+		dc,
+		v1.SchemeGroupVersion.WithResource(v1.TestResultResource),
+		namespace,
+		resync,
+		cache.Indexers{},
+		nil,
+	)
+
+	return &TestResultInformer{
+		Informer: informer.Informer(),
+	}
+}
+
+// AddEventHandler registers typed handlers, tombstones of deleted objects are unwrapped.
+// An update from or to a TestResult that can't be converted is an add or a delete
+func (i *TestResultInformer) AddEventHandler(handler TestResultHandler) {
+
+	i.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if result, ok := toTestResult(obj); ok {
+				handler.OnAdd(result)
+			}
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldResult, oldOk := toTestResult(oldObj)
+			result, ok := toTestResult(obj)
+			switch {
+			case oldOk && ok:
+				handler.OnUpdate(oldResult, result)
+			case ok:
+				handler.OnAdd(result)
+			case oldOk:
+				handler.OnDelete(oldResult)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if result, ok := toTestResult(obj); ok {
+				handler.OnDelete(result)
+			}
+		},
+	})
+}
+
+func (i *TestResultInformer) Run(stopCh <-chan struct{}) {
+	i.Informer.Run(stopCh)
+}
+
+func (f TestResultHandlerFuncs) OnAdd(obj *v1.TestResult) {
+	if f.AddFunc != nil {
+		f.AddFunc(obj)
+	}
+}
+
+func (f TestResultHandlerFuncs) OnUpdate(oldObj, obj *v1.TestResult) {
+	if f.UpdateFunc != nil {
+		f.UpdateFunc(oldObj, obj)
+	}
+}
+
+func (f TestResultHandlerFuncs) OnDelete(obj *v1.TestResult) {
+	if f.DeleteFunc != nil {
+		f.DeleteFunc(obj)
+	}
+}
+
+func toTestResult(obj interface{}) (*v1.TestResult, bool) {
+
+	content, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		logrus.Warningf("Unexpected object in the TestResult informer: %T", obj)
+		return nil, false
+	}
+
+	result := &v1.TestResult{}
+	if err := FromUnstructured(content, result); err != nil {
+		logrus.Warningf("Skipping TestResult: %v", err)
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,74 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	gokubetestv1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	versioned "github.com/ish-xyz/go-kubetest/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/ish-xyz/go-kubetest/pkg/client/informers/externalversions/internalinterfaces"
+	v1 "github.com/ish-xyz/go-kubetest/pkg/client/listers/gokubetest/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// TestFixtureInformer provides access to a shared informer and lister for
+// TestFixtures.
+type TestFixtureInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.TestFixtureLister
+}
+
+type testFixtureInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewTestFixtureInformer constructs a new informer for TestFixture type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewTestFixtureInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredTestFixtureInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredTestFixtureInformer constructs a new informer for TestFixture type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredTestFixtureInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.KubetestV1().TestFixtures(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.KubetestV1().TestFixtures(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&gokubetestv1.TestFixture{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *testFixtureInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredTestFixtureInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *testFixtureInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&gokubetestv1.TestFixture{}, f.defaultInformer)
+}
+
+func (f *testFixtureInformer) Lister() v1.TestFixtureLister {
+	return v1.NewTestFixtureLister(f.Informer().GetIndexer())
+}
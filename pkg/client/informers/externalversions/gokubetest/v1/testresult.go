@@ -0,0 +1,74 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	gokubetestv1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	versioned "github.com/ish-xyz/go-kubetest/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/ish-xyz/go-kubetest/pkg/client/informers/externalversions/internalinterfaces"
+	v1 "github.com/ish-xyz/go-kubetest/pkg/client/listers/gokubetest/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// TestResultInformer provides access to a shared informer and lister for
+// TestResults.
+type TestResultInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.TestResultLister
+}
+
+type testResultInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewTestResultInformer constructs a new informer for TestResult type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewTestResultInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredTestResultInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredTestResultInformer constructs a new informer for TestResult type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredTestResultInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.KubetestV1().TestResults(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.KubetestV1().TestResults(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&gokubetestv1.TestResult{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *testResultInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredTestResultInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *testResultInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&gokubetestv1.TestResult{}, f.defaultInformer)
+}
+
+func (f *testResultInformer) Lister() v1.TestResultLister {
+	return v1.NewTestResultLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Interfaces
+
+// Handlers of the TestResult informer, objects that can't be converted are skipped
+type TestResultHandler interface {
+	OnAdd(obj *v1.TestResult)
+	OnUpdate(oldObj, obj *v1.TestResult)
+	OnDelete(obj *v1.TestResult)
+}
+
+// Informers
+type TestResultInformer struct {
+	Informer cache.SharedIndexInformer
+}
+
+type TestResultHandlerFuncs struct {
+	AddFunc    func(obj *v1.TestResult)
+	UpdateFunc func(oldObj, obj *v1.TestResult)
+	DeleteFunc func(obj *v1.TestResult)
+}
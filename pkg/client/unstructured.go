@@ -0,0 +1,28 @@
+package client
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FromUnstructured converts an object into one of the typed resources, fields of the wrong type are errors
+func FromUnstructured(obj *unstructured.Unstructured, into runtime.Object) error {
+
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), into)
+	if err != nil {
+		return fmt.Errorf("invalid %s %s/%s: %v", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// ToUnstructured converts a typed resource, apiVersion and kind have to be set
+func ToUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
@@ -2,20 +2,85 @@ package controller
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
 	"github.com/ish-xyz/go-kubetest/pkg/assert"
+	"github.com/ish-xyz/go-kubetest/pkg/chaos"
+	"github.com/ish-xyz/go-kubetest/pkg/client"
+	"github.com/ish-xyz/go-kubetest/pkg/internal/helpers"
 	"github.com/ish-xyz/go-kubetest/pkg/loader"
 	"github.com/ish-xyz/go-kubetest/pkg/metrics"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
 )
 
-const defaultMaxWait = "60s"
+// Upper bound of the wait between retries
+const maxRetryBackoff = 5 * time.Minute
+
+// First wait before loading the tests again after an error, doubled up to the interval
+const loadRetryBackoff = 5 * time.Second
+
+// Number of recent runs the flakiness of a test is computed on
+const historySize = 10
+
+// Results in the status of the TestDefinitions
+const (
+	statusPassed  = "Passed"
+	statusFailed  = "Failed"
+	statusSkipped = "Skipped"
+)
+
+// Setup order of the kinds: namespaces, CRDs, RBAC, configuration, storage and services, workloads
+var kindPriority = map[string]int{
+	"Namespace":                0,
+	"CustomResourceDefinition": 1,
+	"ServiceAccount":           2,
+	"ClusterRole":              2,
+	"ClusterRoleBinding":       2,
+	"Role":                     2,
+	"RoleBinding":              2,
+	"ConfigMap":                3,
+	"Secret":                   3,
+	"ResourceQuota":            3,
+	"LimitRange":               3,
+	"StorageClass":             4,
+	"PersistentVolume":         4,
+	"PersistentVolumeClaim":    4,
+	"Service":                  4,
+	"Pod":                      5,
+	"ReplicaSet":               5,
+	"Deployment":               5,
+	"StatefulSet":              5,
+	"DaemonSet":                5,
+	"Job":                      5,
+	"CronJob":                  5,
+}
+
+const customResourcePriority = 6
+
+// Fields populated by the API server, ignored when an object is reverted or recreated
+var serverFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "selfLink"},
+	{"status"},
+}
 
 // Return a new instance for controller
 func NewController(
@@ -43,60 +108,577 @@ func (ctrl *Controller) Run(
 ) error {
 
 	if !once {
+		// The health checks are served by the metrics server
+		ctrl.RegisterHealthChecks(http.DefaultServeMux)
 		logrus.Infof("Starting metrics server at :%d", ctrl.MetricsController.Port)
 		go ctrl.MetricsController.Run(namespace)
 	}
 
 	logrus.Info("Starting controller")
+	err := ctrl.RestoreState(ctx)
+	if err != nil {
+		logrus.Warningf("Can't restore the state of the previous runs: %v", err)
+	}
+
+	var backoff time.Duration
 	for {
-		testsList, err := ctrl.Loader.LoadTests(namespace, selectors)
-		if err != nil {
-			logrus.Fatal(err)
+		failedTests := 0
+		ctrl.CleanupRetained(ctx, time.Now())
+		testsList, err := ctrl.Loader.LoadTests(ctx, namespace, selectors)
+		ctrl.recordLoad(err, time.Now())
+		if err != nil && once {
 			return err
 		}
-
-		for _, test := range testsList {
-			logrus.Infof("Running test: '%s'", test.Name)
-
-			// Create resources and wait for creation
-			asrtRes := make(map[string]interface{})
-			errors := ctrl.Setup(ctx, test.ObjectsList)
-			if !ctrl.WaitForCreation(ctx, test.Setup.WaitFor) {
-				logrus.Errorf("Error while waiting for resource/s to be created, skipping test '%s'", test.Name)
-				asrtRes["wait_for_creation"] = false
-				ctrl.CreateTestResult(ctx, test.Name, false, asrtRes)
-				continue
+		if err != nil {
+			backoff = getLoadBackoff(backoff, wait)
+			logrus.Errorf("Can't load the tests, retrying in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
 			}
+			continue
+		}
+		backoff = 0
+		if len(testsList) == 0 {
+			logrus.Infof("No tests found in namespace %s", namespace)
+		}
 
-			// Run the actual tests
-			result, asrtRes := ctrl.Assert.Run(test, errors)
-
-			// Delete resources and wait for deletion
-			ctrl.Teardown(ctx, test.ObjectsList)
-			if !ctrl.WaitForDeletion(ctx, test.Teardown.WaitFor) {
-				logrus.Errorf("Error while waiting for resource/s to be deleted, test: '%s'", test.Name)
-				result = false
-				asrtRes["wait_for_creation"] = true
-				asrtRes["wait_for_deletion"] = false
-			}
+		ctrl.RunTests(ctx, testsList, func(test *loader.TestDefinition, testResult TestResult) {
 
 			// Create test results
-			asrtRes["wait_for_creation"] = true
-			asrtRes["wait_for_deletion"] = true
-			err = ctrl.CreateTestResult(ctx, test.Name, result, asrtRes)
+			err := ctrl.CreateTestResult(ctx, test.Name, testResult)
 			if err != nil {
 				logrus.Warningf("error creating test results %v", err)
 			}
-		}
+			next := time.Now().Add(wait)
+			if once {
+				next = time.Time{}
+			}
+			err = ctrl.UpdateStatus(ctx, namespace, test, testResult, next)
+			if err != nil {
+				logrus.Warningf("error updating the status of %s: %v", test.Name, err)
+			}
+			if failedAtSeverity(testResult, ctrl.FailSeverity) {
+				failedTests++
+			}
+		})
 
 		if once {
 			logrus.Infof("Tests finished, results have been created")
+			if failedTests > 0 {
+				return fmt.Errorf("%d test/s failed with severity %s or higher", failedTests, getFailSeverity(ctrl.FailSeverity))
+			}
 			return nil
 		}
 
 		logrus.Infof("Waiting for next execution (%s)", wait)
-		time.Sleep(wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// RunTests runs the tests in dependency order, up to Concurrency tests whose dependencies
+// passed run in parallel. Tests whose dependencies didn't pass are skipped, tests with
+// load errors fail without running.
+// Report is called for every test, from the calling goroutine
+func (ctrl *Controller) RunTests(ctx context.Context, tests []*loader.TestDefinition, report func(*loader.TestDefinition, TestResult)) {
+
+	type finished struct {
+		test   *loader.TestDefinition
+		result TestResult
+	}
+
+	loaded := map[string]bool{}
+	for _, test := range tests {
+		loaded[test.Name] = true
+	}
+
+	concurrency := ctrl.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctrl.prepareFixtures(tests)
+
+	passed := map[string]bool{}
+	pending := tests
+	running := 0
+	done := make(chan finished)
+
+	for {
+		// Skipping a test can unblock the ones depending on it, repeat until nothing changes
+		for changed := true; changed; {
+			changed = false
+			var waiting []*loader.TestDefinition
+
+			for _, test := range pending {
+				reason, ready := dependencyState(test, loaded, passed)
+				switch {
+				case len(test.LoadErrors) > 0:
+					logrus.Errorf("Test '%s' couldn't be loaded: %s", test.Name, strings.Join(test.LoadErrors, "; "))
+					passed[test.Name] = false
+					ctrl.releaseFixtures(ctx, test)
+					report(test, ctrl.recordRun(test.Name, loadFailedResult(test.LoadErrors)))
+					changed = true
+				case !ready || (reason == "" && running >= concurrency):
+					waiting = append(waiting, test)
+				case reason != "":
+					logrus.Warningf("Skipping test '%s': %s", test.Name, reason)
+					passed[test.Name] = false
+					ctrl.releaseFixtures(ctx, test)
+					report(test, ctrl.recordRun(test.Name, skippedResult(reason)))
+					changed = true
+				default:
+					logrus.Infof("Running test: '%s'", test.Name)
+					running++
+					go func(test *loader.TestDefinition) {
+						done <- finished{test: test, result: ctrl.runWithFixtures(ctx, test)}
+					}(test)
+				}
+			}
+			pending = waiting
+		}
+
+		if running == 0 {
+			// Only possible with a dependency cycle, the loader adds a load error to them
+			for _, test := range pending {
+				ctrl.releaseFixtures(ctx, test)
+				report(test, ctrl.recordRun(test.Name, skippedResult("dependency cycle")))
+			}
+			return
+		}
+
+		f := <-done
+		running--
+		passed[f.test.Name] = f.result.Result
+		report(f.test, ctrl.recordRun(f.test.Name, f.result))
+	}
+}
+
+// Run a test until it passes or runs out of retries, the backoff
+// starts at RetryBackoff and doubles after every failed retry
+func (ctrl *Controller) runWithRetries(ctx context.Context, test *loader.TestDefinition) TestResult {
+
+	backoff := ctrl.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		testResult := ctrl.RunTest(ctx, test)
+		testResult.Attempts = int32(attempt)
+		if testResult.Result || attempt > test.Retries {
+			return testResult
+		}
+
+		logrus.Warningf("Test '%s' failed, retrying in %s (%d/%d)", test.Name, backoff, attempt, test.Retries)
+		select {
+		case <-ctx.Done():
+			return testResult
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// Add the outcome of a run to the history of the test and set its flakiness,
+// skipped runs don't change the history
+func (ctrl *Controller) recordRun(name string, testResult TestResult) TestResult {
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	if ctrl.history == nil {
+		ctrl.history = map[string][]runOutcome{}
+	}
+
+	runs := ctrl.history[name]
+	if !testResult.Skipped {
+		runs = append(runs, runOutcome{Passed: testResult.Result, Attempts: testResult.Attempts})
+		if len(runs) > historySize {
+			runs = runs[len(runs)-historySize:]
+		}
+		ctrl.history[name] = runs
+	}
+
+	testResult.History = append([]runOutcome(nil), runs...)
+	testResult.Flakiness = flakiness(runs)
+	testResult.Flaky = testResult.Flakiness > 0
+	return testResult
+}
+
+// RunTest runs setup, assertions and steps of a test, teardown deletes every
+// object created along the way, including the ones applied by the steps
+func (ctrl *Controller) RunTest(ctx context.Context, test *loader.TestDefinition) TestResult {
+
+	testResult := TestResult{
+		Result:     true,
+		Assertions: map[string]bool{},
+		Messages:   map[string]string{},
+		Severities: map[string]string{},
+	}
+	run := &testRun{created: newCreatedObjects()}
+
+	// Start from a clean state if the resources of the previous run were retained
+	releaseCtx, cancelRelease := ctrl.teardownContext(ctx)
+	ctrl.releaseRetained(releaseCtx, test.Name)
+	cancelRelease()
+
+	// Setup, assertions and steps share the test timeout, the loader validated it
+	testCtx := ctx
+	if test.Timeout != "" {
+		timeout, _ := time.ParseDuration(test.Timeout)
+		var cancel context.CancelFunc
+		testCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Create resources and wait for creation
+	errors := ctrl.Setup(testCtx, test.ObjectsList)
+	run.created.add(test.ObjectsList...)
+	testResult.Assertions["wait_for_creation"] = ctrl.WaitForCreation(testCtx, test.Setup.WaitFor)
+
+	if !testResult.Assertions["wait_for_creation"] {
+		logrus.Errorf("Error while waiting for resource/s to be created, skipping test '%s'", test.Name)
+		testResult.Result = false
+	} else {
+		// Run the actual tests, then the steps in order
+		result, asrtRes := ctrl.Assert.Run(testCtx, test, errors)
+		addAssertionResults(&testResult, "", asrtRes)
+		testResult.Result = result
+
+		for index, step := range test.Steps {
+			if !testResult.Result {
+				logrus.Warningf("Skipping %d step/s of test '%s' after a failure", len(test.Steps)-index, test.Name)
+				break
+			}
+			if testResult.Steps == nil {
+				testResult.Steps = map[string]bool{}
+			}
+			testResult.Steps[step.Name] = ctrl.runStep(testCtx, step, run, &testResult)
+			testResult.Result = testResult.Steps[step.Name]
+		}
+	}
+
+	if testCtx.Err() == context.DeadlineExceeded {
+		logrus.Errorf("Test '%s' timed out after %s", test.Name, test.Timeout)
+		testResult.Result = false
+		testResult.Assertions["timeout"] = false
+		testResult.Messages["timeout"] = fmt.Sprintf("test didn't finish within %s", test.Timeout)
+	}
+
+	// Collect diagnostics before the resources are deleted, with their own budget
+	if !testResult.Result && ctrl.Diagnostics != nil {
+		diagCtx, cancelDiag := ctrl.diagnosticsContext(ctx)
+		ctrl.collectDiagnostics(diagCtx, test, &testResult)
+		cancelDiag()
+	}
+
+	// The teardown budget starts now, whatever the time spent by the test and the diagnostics
+	teardownCtx, cancelTeardown := ctrl.teardownContext(ctx)
+	defer cancelTeardown()
+
+	if ctrl.keepResources(test, testResult.Result) {
+		logrus.Warningf("Keeping the resources of test '%s' (teardown policy)", test.Name)
+		// Only the resources created by the test are kept, patches and chaos actions
+		// on the other objects are always reverted
+		ctrl.Restore(teardownCtx, run.restores)
+		run.restores = nil
+		expires := ctrl.retain(test.Name, run)
+		testResult.Retained = resourcePaths(run.created.list())
+		if !expires.IsZero() {
+			testResult.RetainedUntil = expires.UTC().Format(time.RFC3339)
+		}
+		return testResult
+	}
+
+	// Revert patches and chaos actions, then delete resources and wait for deletion
+	ctrl.cleanup(teardownCtx, run)
+	testResult.Assertions["wait_for_deletion"] = true
+	if !ctrl.WaitForDeletion(teardownCtx, test.Teardown.WaitFor) {
+		logrus.Errorf("Error while waiting for resource/s to be deleted, test: '%s'", test.Name)
+		testResult.Result = false
+		testResult.Assertions["wait_for_deletion"] = false
+	}
+
+	return testResult
+}
+
+// Run a single step, its results are recorded as <step>.<assertion>
+func (ctrl *Controller) runStep(ctx context.Context, step loader.Step, run *testRun, testResult *TestResult) bool {
+
+	logrus.Debugf("Running step: '%s'", step.Name)
+
+	errors := ctrl.Setup(ctx, step.ApplyObjects)
+	run.created.add(step.ApplyObjects...)
+	errors = append(errors, ctrl.patchObjects(ctx, step.PatchObjects, run)...)
+	deleteErrors := ctrl.deleteObjects(ctx, step.DeleteObjects, run)
+	errors = append(errors, deleteErrors...)
+
+	prefix := fmt.Sprintf("%s.", step.Name)
+	for _, action := range step.Actions {
+		err := ctrl.runObjectAction(ctx, action, run)
+		testResult.Assertions[prefix+action.Name] = err == nil
+		if err != nil {
+			logrus.Errorf("Action '%s' failed, step: '%s': %v", action.Name, step.Name, err)
+			testResult.Messages[prefix+action.Name] = err.Error()
+			return false
+		}
+	}
+
+	for _, action := range step.Chaos {
+		restore, err := ctrl.runChaos(ctx, action)
+		if restore != nil {
+			run.restores = append(run.restores, restore)
+		}
+		testResult.Assertions[prefix+action.Name] = err == nil
+		if err != nil {
+			logrus.Errorf("Chaos action '%s' failed, step: '%s': %v", action.Name, step.Name, err)
+			testResult.Messages[prefix+action.Name] = err.Error()
+			return false
+		}
+	}
+
+	if !ctrl.WaitForCreation(ctx, step.WaitFor) {
+		logrus.Errorf("Error while waiting for resource/s to be created, step: '%s'", step.Name)
+		testResult.Assertions[prefix+"wait_for_creation"] = false
+		return false
+	}
+	if !ctrl.WaitForDeletion(ctx, step.WaitForDeletion) {
+		logrus.Errorf("Error while waiting for resource/s to be deleted, step: '%s'", step.Name)
+		testResult.Assertions[prefix+"wait_for_deletion"] = false
+		return false
+	}
+
+	result, asrtRes := ctrl.Assert.RunAssertions(ctx, step.Assert, errors)
+	addAssertionResults(testResult, prefix, asrtRes)
+
+	// The delete errors are visible to expectedErrors, the step fails anyway
+	if len(deleteErrors) > 0 {
+		messages := make([]string, len(deleteErrors))
+		for index, objErr := range deleteErrors {
+			messages[index] = objErr.Error()
+		}
+		logrus.Errorf("Error while deleting resource/s, step: '%s'", step.Name)
+		testResult.Assertions[prefix+"delete"] = false
+		testResult.Messages[prefix+"delete"] = strings.Join(messages, "; ")
+		return false
+	}
+
+	return result
+}
+
+func (ctrl *Controller) runChaos(ctx context.Context, action loader.ChaosAction) (chaos.Restore, error) {
+
+	if ctrl.Chaos == nil {
+		return nil, fmt.Errorf("chaos actions are disabled")
+	}
+	return ctrl.Chaos.Run(ctx, action)
+}
+
+// Run the functions reverting the actions of the steps, from the last one to the first one
+func (ctrl *Controller) Restore(ctx context.Context, restores []func(context.Context) error) []string {
+
+	var errors []string
+
+	for index := range restores {
+		err := restores[len(restores)-1-index](ctx)
+		if err != nil {
+			logrus.Warningf("Couldn't revert action: %v", err)
+			errors = append(errors, fmt.Sprintf("%v", err))
+		}
+	}
+	return errors
+}
+
+// Write the diagnostics bundle of a failed test, a summary is attached
+// to the TestResult if no diagnostics directory is configured
+func (ctrl *Controller) collectDiagnostics(ctx context.Context, test *loader.TestDefinition, testResult *TestResult) {
+
+	logrus.Infof("Collecting diagnostics of test '%s'", test.Name)
+
+	// The objects of the failed sub-assertions are collected too
+	results := map[string]bool{}
+	for key, passed := range testResult.SubAssertions {
+		results[key] = passed
+	}
+	for key, passed := range testResult.Assertions {
+		results[key] = passed
+	}
+	bundle := ctrl.Diagnostics.Collect(ctx, test, results)
+
+	if ctrl.Diagnostics.Dir == "" {
+		testResult.Diagnostics = bundle.Summary(ctrl.Diagnostics.MaxSummarySize)
+		return
+	}
+
+	path, err := bundle.Write(ctrl.Diagnostics.Dir)
+	if err != nil {
+		logrus.Warningf("error writing diagnostics of test '%s': %v", test.Name, err)
+		testResult.Diagnostics = bundle.Summary(ctrl.Diagnostics.MaxSummarySize)
+		return
+	}
+	testResult.DiagnosticsPath = path
+}
+
+// Check if the resources of a test have to be kept for debugging,
+// --keep-on-failure takes precedence over the "always" policy
+func (ctrl *Controller) keepResources(test *loader.TestDefinition, passed bool) bool {
+
+	policy := test.TeardownPolicy
+	if policy == "" {
+		policy = ctrl.TeardownPolicy
+	}
+
+	switch policy {
+	case loader.TeardownNever:
+		return true
+	case loader.TeardownOnSuccess:
+		return !passed
+	}
+	return !passed && ctrl.KeepOnFailure
+}
+
+// Keep the resources of a test until the retention TTL expires, a zero time means no expiration
+func (ctrl *Controller) retain(name string, run *testRun) time.Time {
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	var expires time.Time
+	if ctrl.RetentionTTL > 0 {
+		expires = time.Now().Add(ctrl.RetentionTTL)
+	}
+	if ctrl.retained == nil {
+		ctrl.retained = map[string]*retention{}
+	}
+	ctrl.retained[name] = &retention{run: run, expires: expires}
+
+	return expires
+}
+
+// RestoreState rebuilds the run history and the retention of the resources from the
+// TestResults of the previous runs, so that they survive a restart of the controller
+func (ctrl *Controller) RestoreState(ctx context.Context) error {
+
+	results, err := ctrl.Provisioner.ListWithSelectors(
+		ctx,
+		map[string]string{
+			"apiVersion": v1.SchemeGroupVersion.String(),
+			"kind":       "TestResult",
+			"namespace":  "",
+		},
+		map[string]interface{}{},
+	)
+	if err != nil {
+		return err
+	}
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	for index := range results.Items {
+		result := &v1.TestResult{}
+		if err := client.FromUnstructured(&results.Items[index], result); err != nil {
+			logrus.Warningf("Can't restore the state of a previous run: %v", err)
+			continue
+		}
+		ctrl.restoreHistory(result)
+		ctrl.restoreRetention(result)
+	}
+
+	return nil
+}
+
+// Restore the history of a test, unless runs have already been recorded
+func (ctrl *Controller) restoreHistory(result *v1.TestResult) {
+
+	if ctrl.history == nil {
+		ctrl.history = map[string][]runOutcome{}
+	}
+	runs := result.Spec.History
+	if len(runs) == 0 || ctrl.history[result.Name] != nil {
+		return
+	}
+	if len(runs) > historySize {
+		runs = runs[len(runs)-historySize:]
+	}
+	ctrl.history[result.Name] = append([]runOutcome(nil), runs...)
+}
+
+// Restore the retention of the resources of a test, unless they're already retained
+func (ctrl *Controller) restoreRetention(result *v1.TestResult) {
+
+	if ctrl.retained == nil {
+		ctrl.retained = map[string]*retention{}
+	}
+	if len(result.Spec.Retained) == 0 || ctrl.retained[result.Name] != nil {
+		return
+	}
+
+	run := &testRun{created: newCreatedObjects()}
+	for _, path := range result.Spec.Retained {
+		obj, err := getObjectFromPath(path)
+		if err != nil {
+			logrus.Warningf("Can't restore the retained resource %s of test '%s': %v", path, result.Name, err)
+			continue
+		}
+		run.created.add(obj)
+	}
+
+	// Without a valid expiration the resources are kept until the test runs again
+	var expires time.Time
+	if result.Spec.RetainedUntil != "" {
+		var err error
+		expires, err = time.Parse(time.RFC3339, result.Spec.RetainedUntil)
+		if err != nil {
+			logrus.Warningf("Invalid retainedUntil in the TestResult of test '%s': %v", result.Name, err)
+		}
 	}
+	ctrl.retained[result.Name] = &retention{run: run, expires: expires}
+}
+
+// Clean the retained resources of a test, if any
+func (ctrl *Controller) releaseRetained(ctx context.Context, name string) {
+
+	ctrl.mu.Lock()
+	kept, ok := ctrl.retained[name]
+	delete(ctrl.retained, name)
+	ctrl.mu.Unlock()
+
+	if ok {
+		logrus.Infof("Cleaning the retained resources of test '%s'", name)
+		ctrl.cleanup(ctx, kept.run)
+	}
+}
+
+// CleanupRetained cleans the retained resources whose TTL expired before now
+func (ctrl *Controller) CleanupRetained(ctx context.Context, now time.Time) {
+
+	var expired []string
+
+	ctrl.mu.Lock()
+	for name, kept := range ctrl.retained {
+		if !kept.expires.IsZero() && kept.expires.Before(now) {
+			expired = append(expired, name)
+		}
+	}
+	ctrl.mu.Unlock()
+
+	for _, name := range expired {
+		ctrl.releaseRetained(ctx, name)
+	}
+}
+
+// Revert patches and chaos actions, then delete the resources created by the test
+func (ctrl *Controller) cleanup(ctx context.Context, run *testRun) {
+
+	ctrl.Restore(ctx, run.restores)
+	ctrl.Teardown(ctx, run.created.list())
 }
 
 // WaitForCreation wait until a set of resources has been created
@@ -104,13 +686,13 @@ func (ctrl *Controller) WaitForCreation(ctx context.Context, resources []loader.
 
 	for _, resource := range resources {
 
-		gvkData, err := getResourceDataFromPath(resource.Resource)
+		gvkData, err := helpers.ParseObjectPath(resource.Resource)
 		if err != nil {
 			logrus.Debugf("%v", err)
 			return false
 		}
 		created, interval := false, 2
-		limit := getMaxRetries(resource.Timeout, interval)
+		limit := helpers.MaxRetries(resource.Timeout, interval)
 
 		logrus.Debugf("Waiting for resource %s, retrying every %ds for %d times", resource.Resource, interval, limit)
 		for counter := 0; counter < limit; counter++ {
@@ -131,7 +713,9 @@ func (ctrl *Controller) WaitForCreation(ctx context.Context, resources []loader.
 					break
 				}
 			}
-			time.Sleep(time.Duration(interval) * time.Second)
+			if !helpers.Sleep(ctx, interval) {
+				break
+			}
 		}
 		if !created {
 			return false
@@ -145,18 +729,18 @@ func (ctrl *Controller) WaitForDeletion(ctx context.Context, resources []loader.
 
 	for _, resource := range resources {
 
-		gvkData, err := getResourceDataFromPath(resource.Resource)
+		gvkData, err := helpers.ParseObjectPath(resource.Resource)
 		if err != nil {
 			logrus.Debugf("%v", err)
 			return false
 		}
 		deleted, interval := false, 2
-		limit := getMaxRetries(resource.Timeout, interval)
+		limit := helpers.MaxRetries(resource.Timeout, interval)
 
 		logrus.Debugf("Waiting for resource %s, retrying every %ds for %d times", resource.Resource, interval, limit)
 		for counter := 0; counter < limit; counter++ {
 
-			obj, _ := ctrl.Provisioner.ListWithSelectors(
+			obj, err := ctrl.Provisioner.ListWithSelectors(
 				ctx,
 				gvkData,
 				map[string]interface{}{
@@ -172,7 +756,9 @@ func (ctrl *Controller) WaitForDeletion(ctx context.Context, resources []loader.
 					break
 				}
 			}
-			time.Sleep(time.Duration(interval) * time.Second)
+			if !helpers.Sleep(ctx, interval) {
+				break
+			}
 		}
 		if !deleted {
 			return false
@@ -182,33 +768,227 @@ func (ctrl *Controller) WaitForDeletion(ctx context.Context, resources []loader.
 	return true
 }
 
-// Create resources defined on manifests
-func (ctrl *Controller) Setup(ctx context.Context, objects []*unstructured.Unstructured) []string {
+// Create resources defined on manifests, ordered by kind priority.
+// New CRDs have to be established before the following objects are applied
+func (ctrl *Controller) Setup(ctx context.Context, objects []*unstructured.Unstructured) []provisioner.ObjectError {
 
-	var errors []string
+	var errors []provisioner.ObjectError
+	var crds []*unstructured.Unstructured
+
+	for _, obj := range sortByKind(objects) {
+		if len(crds) > 0 && !isCRD(obj) {
+			errors = append(errors, ctrl.waitForCRDs(ctx, crds)...)
+			crds = nil
+		}
 
-	for _, obj := range objects {
 		err := ctrl.Provisioner.CreateOrUpdate(ctx, obj)
 		if err != nil {
 			logrus.Debugf("Couldn't create resource %s", obj.GetName())
 			logrus.Debugln(err)
-			errors = append(errors, fmt.Sprintf("%v", err))
+			errors = append(errors, provisioner.NewObjectError(obj, err))
 			continue
 		}
 		logrus.Debugf("Setup: resource created %s\n", obj.GetName())
+
+		if isCRD(obj) {
+			crds = append(crds, obj)
+		}
+	}
+
+	// Assertions and steps may use the custom resources as well
+	if len(crds) > 0 {
+		errors = append(errors, ctrl.waitForCRDs(ctx, crds)...)
+	}
+
+	return errors
+}
+
+// Wait for the CRDs to be established, then refresh the discovery information
+// so that their custom resources can be applied
+func (ctrl *Controller) waitForCRDs(ctx context.Context, crds []*unstructured.Unstructured) []provisioner.ObjectError {
+
+	var errors []provisioner.ObjectError
+	interval := 2
+	limit := helpers.MaxRetries(helpers.DefaultTimeout, interval)
+
+	for _, crd := range crds {
+		established := false
+		for counter := 0; counter < limit; counter++ {
+			live, err := ctrl.Provisioner.Get(ctx, crd)
+			if err == nil && isEstablished(live) {
+				established = true
+				break
+			}
+			logrus.Debugf("Waiting for CRD %s to be established", crd.GetName())
+			if !helpers.Sleep(ctx, interval) {
+				break
+			}
+		}
+		if !established {
+			errors = append(errors, provisioner.NewObjectError(crd, fmt.Errorf("CRD not established after %s", helpers.DefaultTimeout)))
+		}
+	}
+
+	ctrl.Provisioner.ResetMapper()
+	return errors
+}
+
+// Merge the manifests into the existing objects, teardown reverts the patches
+func (ctrl *Controller) patchObjects(ctx context.Context, objects []*unstructured.Unstructured, run *testRun) []provisioner.ObjectError {
+
+	var errors []provisioner.ObjectError
+
+	for _, obj := range objects {
+		data, err := obj.MarshalJSON()
+		if err == nil {
+			err = ctrl.patchObject(ctx, obj, types.MergePatchType, data, run)
+		}
+		if err != nil {
+			logrus.Debugf("Couldn't patch resource %s", obj.GetName())
+			logrus.Debugln(err)
+			errors = append(errors, provisioner.NewObjectError(obj, err))
+			continue
+		}
+		logrus.Debugf("Patch: resource patched %s\n", obj.GetName())
 	}
 
 	return errors
 }
 
+// Delete the objects in the reverse setup order, teardown recreates the ones the test didn't create
+func (ctrl *Controller) deleteObjects(ctx context.Context, objects []*unstructured.Unstructured, run *testRun) []provisioner.ObjectError {
+
+	var errors []provisioner.ObjectError
+
+	objects = sortByKind(objects)
+	for index := range objects {
+		obj := objects[len(objects)-1-index]
+		err := ctrl.deleteObject(ctx, obj, run)
+		if err != nil {
+			logrus.Debugf("Couldn't delete resource %s", obj.GetName())
+			logrus.Debugln(err)
+			errors = append(errors, provisioner.NewObjectError(obj, err))
+			continue
+		}
+		logrus.Debugf("Delete: resource deleted %s\n", obj.GetName())
+	}
+
+	return errors
+}
+
+// Patch or delete an existing object
+func (ctrl *Controller) runObjectAction(ctx context.Context, action loader.ObjectAction, run *testRun) error {
+
+	obj, err := getObjectFromPath(action.Resource)
+	if err != nil {
+		return err
+	}
+
+	if action.Type == "delete" {
+		return ctrl.deleteObject(ctx, obj, run)
+	}
+
+	data, err := yaml.YAMLToJSON([]byte(action.Patch))
+	if err != nil {
+		return err
+	}
+	return ctrl.patchObject(ctx, obj, getPatchType(action.PatchType), data, run)
+}
+
+// Patch an object capturing its original state, so that teardown can revert the patch.
+// Objects created by the test are deleted by teardown, there's nothing to revert
+func (ctrl *Controller) patchObject(ctx context.Context, obj *unstructured.Unstructured, patchType types.PatchType, data []byte, run *testRun) error {
+
+	var original *unstructured.Unstructured
+	var err error
+
+	if !run.created.has(obj) {
+		original, err = ctrl.Provisioner.Get(ctx, obj)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ctrl.Provisioner.Patch(ctx, obj, patchType, data)
+	if err != nil {
+		return err
+	}
+
+	if original != nil {
+		run.restores = append(run.restores, func(ctx context.Context) error {
+			return ctrl.revertPatch(ctx, original)
+		})
+	}
+	return nil
+}
+
+// Delete an object capturing its original state, so that teardown can recreate it
+func (ctrl *Controller) deleteObject(ctx context.Context, obj *unstructured.Unstructured, run *testRun) error {
+
+	// Objects that couldn't be deleted are still deleted by teardown
+	if run.created.has(obj) {
+		err := ctrl.Provisioner.Delete(ctx, obj)
+		if err == nil {
+			run.created.remove(obj)
+		}
+		return err
+	}
+
+	original, err := ctrl.Provisioner.Get(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.Provisioner.Delete(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	run.restores = append(run.restores, func(ctx context.Context) error {
+		return ctrl.Provisioner.CreateOrUpdate(ctx, withoutServerFields(original))
+	})
+	return nil
+}
+
+// Bring an object back to its original state with a merge patch
+// computed between the live state and the original one
+func (ctrl *Controller) revertPatch(ctx context.Context, original *unstructured.Unstructured) error {
+
+	live, err := ctrl.Provisioner.Get(ctx, original)
+	if err != nil {
+		return err
+	}
+
+	liveJSON, err := withoutServerFields(live).MarshalJSON()
+	if err != nil {
+		return err
+	}
+	originalJSON, err := withoutServerFields(original).MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	patch, err := jsonpatch.CreateMergePatch(liveJSON, originalJSON)
+	if err != nil {
+		return err
+	}
+	if string(patch) == "{}" {
+		return nil
+	}
+
+	logrus.Debugf("Reverting patch on resource %s", original.GetName())
+	return ctrl.Provisioner.Patch(ctx, original, types.MergePatchType, patch)
+}
+
 // Delete resources defined on manifests
 func (ctrl *Controller) Teardown(ctx context.Context, objects []*unstructured.Unstructured) []string {
 
 	var errors []string
 
+	objects = sortByKind(objects)
 	for index := range objects {
-		// Teardown needs to delete the objects in the
-		// manifest, from the last one to the first one
+		// Teardown needs to delete the objects in the reverse
+		// setup order, from the last one to the first one
 		obj := objects[len(objects)-1-index]
 		err := ctrl.Provisioner.Delete(ctx, obj)
 		if err != nil {
@@ -223,61 +1003,335 @@ func (ctrl *Controller) Teardown(ctx context.Context, objects []*unstructured.Un
 }
 
 // CreateTestResult resource
-func (ctrl *Controller) CreateTestResult(ctx context.Context, name string, result bool, asrtRes map[string]interface{}) error {
+func (ctrl *Controller) CreateTestResult(ctx context.Context, name string, testResult TestResult) error {
 
-	obj := &unstructured.Unstructured{
-		Object: map[string]interface{}{
-			"apiVersion": "go-kubetest.io/v1",
-			"kind":       "TestResult",
-			"metadata": map[string]interface{}{
-				"name": name,
-			},
-			"spec": map[string]interface{}{
-				"result":     result,
-				"assertions": asrtRes,
-			},
+	obj, err := client.ToUnstructured(&v1.TestResult{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "TestResult",
 		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: testResult,
+	})
+	if err != nil {
+		return err
 	}
 
-	err := ctrl.Provisioner.CreateOrUpdate(ctx, obj)
+	err = ctrl.Provisioner.CreateOrUpdate(ctx, obj)
 	return err
 }
 
-func getResourceDataFromPath(resourcePath string) (map[string]string, error) {
+// UpdateStatus sets the status of the TestDefinition after a run, a zero next
+// clears the next run time. Does nothing without a client
+func (ctrl *Controller) UpdateStatus(ctx context.Context, namespace string, test *loader.TestDefinition, testResult TestResult, next time.Time) error {
 
-	path := strings.TrimSuffix(strings.TrimPrefix(resourcePath, ":"), ":")
-	gvk := strings.Split(path, ":")
+	if ctrl.Client == nil {
+		return nil
+	}
 
-	if len(gvk) < 3 {
-		err := errors.New("can't retrieve gvk from resourcePath")
-		return map[string]string{}, err
+	status := map[string]interface{}{
+		"lastResult":  getStatusResult(testResult),
+		"lastRunTime": time.Now().UTC().Format(time.RFC3339),
+		"nextRunTime": nil,
+		"loadErrors":  nil,
+	}
+	if !next.IsZero() {
+		status["nextRunTime"] = next.UTC().Format(time.RFC3339)
+	}
+	if len(test.LoadErrors) > 0 {
+		status["loadErrors"] = test.LoadErrors
 	}
 
-	if len(gvk) > 3 {
-		return map[string]string{
-			"apiVersion": gvk[0],
-			"kind":       gvk[1],
-			"namespace":  gvk[2],
-			"name":       gvk[3],
-		}, nil
+	data, err := json.Marshal(map[string]interface{}{"status": status})
+	if err != nil {
+		return err
 	}
+	_, err = ctrl.Client.KubetestV1().TestDefinitions(namespace).Patch(ctx, test.Name, types.MergePatchType, data, metav1.PatchOptions{}, "status")
+	return err
+}
 
-	return map[string]string{
-		"apiVersion": gvk[0],
-		"kind":       gvk[1],
-		"namespace":  "",
-		"name":       gvk[2],
-	}, nil
+// Record the assertion results, prefix is empty for the top-level assertions.
+// Sub-assertions are kept apart, only their composite assertion counts
+func addAssertionResults(testResult *TestResult, prefix string, asrtRes map[string]assert.Result) {
 
+	for name, res := range asrtRes {
+		if res.Message != "" {
+			testResult.Messages[prefix+name] = res.Message
+		}
+		if res.Nested {
+			if testResult.SubAssertions == nil {
+				testResult.SubAssertions = map[string]bool{}
+			}
+			testResult.SubAssertions[prefix+name] = res.Passed
+			continue
+		}
+		testResult.Assertions[prefix+name] = res.Passed
+		testResult.Severities[prefix+name] = res.Severity
+		if !res.Passed && res.Severity != loader.SeverityCritical {
+			testResult.Warnings = append(testResult.Warnings, prefix+name)
+		}
+	}
+	sort.Strings(testResult.Warnings)
+}
+
+func newCreatedObjects() *createdObjects {
+	return &createdObjects{keys: map[string]bool{}}
+}
+
+// Track objects in creation order, objects applied twice are tracked once
+func (c *createdObjects) add(objects ...*unstructured.Unstructured) {
+
+	for _, obj := range objects {
+		key := objectKey(obj)
+		if c.keys[key] {
+			continue
+		}
+		c.keys[key] = true
+		c.objects = append(c.objects, obj)
+	}
 }
 
-func getMaxRetries(waitTime string, interval int) int {
+// Stop tracking objects that have already been deleted
+func (c *createdObjects) remove(objects ...*unstructured.Unstructured) {
 
-	// Get max wait time and retries/interval
-	maxWait, err := time.ParseDuration(waitTime)
+	for _, obj := range objects {
+		key := objectKey(obj)
+		if !c.keys[key] {
+			continue
+		}
+		delete(c.keys, key)
+		for index := range c.objects {
+			if objectKey(c.objects[index]) == key {
+				c.objects = append(c.objects[:index], c.objects[index+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (c *createdObjects) has(obj *unstructured.Unstructured) bool {
+	return c.keys[objectKey(obj)]
+}
+
+func (c *createdObjects) list() []*unstructured.Unstructured {
+	return c.objects
+}
+
+// The API version is left out, applying a new version of an object updates the same object
+func objectKey(obj *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s:%s:%s", obj.GroupVersionKind().GroupKind(), obj.GetNamespace(), obj.GetName())
+}
+
+func getObjectFromPath(resourcePath string) (*unstructured.Unstructured, error) {
+
+	gvkData, err := helpers.ParseObjectPath(resourcePath)
 	if err != nil {
-		maxWait, _ = time.ParseDuration(defaultMaxWait)
+		return nil, err
 	}
-	return int(maxWait.Seconds()) / interval
 
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(gvkData["apiVersion"])
+	obj.SetKind(gvkData["kind"])
+	obj.SetNamespace(gvkData["namespace"])
+	obj.SetName(gvkData["name"])
+	return obj, nil
+}
+
+// Sort objects by kind priority, objects with the same priority keep their order
+func sortByKind(objects []*unstructured.Unstructured) []*unstructured.Unstructured {
+
+	sorted := make([]*unstructured.Unstructured, len(objects))
+	copy(sorted, objects)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return getKindPriority(sorted[i]) < getKindPriority(sorted[j])
+	})
+	return sorted
+}
+
+// Kinds not listed in kindPriority (e.g. custom resources) are applied last
+func getKindPriority(obj *unstructured.Unstructured) int {
+
+	if priority, ok := kindPriority[obj.GetKind()]; ok {
+		return priority
+	}
+	return customResourcePriority
+}
+
+func isCRD(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() == "CustomResourceDefinition" && obj.GroupVersionKind().Group == "apiextensions.k8s.io"
+}
+
+func isEstablished(crd *unstructured.Unstructured) bool {
+
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, condition := range conditions {
+		fields, _ := condition.(map[string]interface{})
+		if fields["type"] == "Established" && fields["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// Return why a test has to be skipped, ready is false until its dependencies finished
+func dependencyState(test *loader.TestDefinition, loaded, passed map[string]bool) (string, bool) {
+
+	ready := true
+	for _, dependency := range test.DependsOn {
+		if !loaded[dependency] {
+			return fmt.Sprintf("dependency '%s' not found", dependency), true
+		}
+		dependencyPassed, finished := passed[dependency]
+		if !finished {
+			ready = false
+			continue
+		}
+		if !dependencyPassed {
+			return fmt.Sprintf("dependency '%s' didn't pass", dependency), true
+		}
+	}
+	return "", ready
+}
+
+// Share of the runs that passed only on retry or whose result flipped
+// and flipped back (e.g. pass, fail, pass), between 0 and 1
+func flakiness(runs []runOutcome) float64 {
+
+	if len(runs) == 0 {
+		return 0
+	}
+
+	flaky := 0
+	for i, run := range runs {
+		passedOnRetry := run.Passed && run.Attempts > 1
+		flippedBack := i > 1 && run.Passed != runs[i-1].Passed && run.Passed == runs[i-2].Passed
+		if passedOnRetry || flippedBack {
+			flaky++
+		}
+	}
+	return float64(flaky) / float64(len(runs))
+}
+
+func getStatusResult(testResult TestResult) string {
+
+	if testResult.Skipped {
+		return statusSkipped
+	}
+	if testResult.Result {
+		return statusPassed
+	}
+	return statusFailed
+}
+
+// Result of a test that couldn't be loaded, the errors are the messages of the load phase
+func loadFailedResult(loadErrors []string) TestResult {
+	return TestResult{
+		Assertions: map[string]bool{"load": false},
+		Messages:   map[string]string{"load": strings.Join(loadErrors, "; ")},
+		LoadErrors: loadErrors,
+	}
+}
+
+func skippedResult(reason string) TestResult {
+	return TestResult{
+		Assertions: map[string]bool{},
+		Skipped:    true,
+		SkipReason: reason,
+	}
+}
+
+func resourcePaths(objects []*unstructured.Unstructured) []string {
+
+	var paths []string
+	for _, obj := range objects {
+		paths = append(paths, helpers.ObjectPath(obj))
+	}
+	return paths
+}
+
+// Return a copy of the object without the fields populated by the API server
+func withoutServerFields(obj *unstructured.Unstructured) *unstructured.Unstructured {
+
+	clean := obj.DeepCopy()
+	for _, field := range serverFields {
+		unstructured.RemoveNestedField(clean.Object, field...)
+	}
+	return clean
+}
+
+func getPatchType(patchType string) types.PatchType {
+
+	switch patchType {
+	case "json":
+		return types.JSONPatchType
+	case "strategic":
+		return types.StrategicMergePatchType
+	}
+	return types.MergePatchType
+}
+
+// Check if any assertion failed with a severity equal or higher than the threshold,
+// entries without severity (e.g. wait_for_creation) are critical and skipped tests failed
+func failedAtSeverity(testResult TestResult, threshold string) bool {
+
+	if testResult.Skipped {
+		return true
+	}
+
+	minRank := loader.SeverityRank(getFailSeverity(threshold))
+	for name, passed := range testResult.Assertions {
+		if passed {
+			continue
+		}
+		severity, ok := testResult.Severities[name]
+		if !ok {
+			severity = loader.SeverityCritical
+		}
+		if loader.SeverityRank(severity) >= minRank {
+			return true
+		}
+	}
+	return false
+}
+
+func getFailSeverity(threshold string) string {
+
+	if threshold == "" {
+		return loader.SeverityCritical
+	}
+	return threshold
+}
+
+// Return the wait before the next load of the tests, previous is 0 after a successful load
+func getLoadBackoff(previous, wait time.Duration) time.Duration {
+
+	backoff := previous * 2
+	if previous == 0 {
+		backoff = loadRetryBackoff
+	}
+	if backoff > wait {
+		backoff = wait
+	}
+	return backoff
+}
+
+// Return the context of the teardown, independent of the test timeout
+// so that cleanup still happens after the test ran out of time
+func (ctrl *Controller) teardownContext(ctx context.Context) (context.Context, context.CancelFunc) {
+
+	if ctrl.TeardownTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, ctrl.TeardownTimeout)
+}
+
+// Return the context of the diagnostics collection, independent of the teardown budget
+func (ctrl *Controller) diagnosticsContext(ctx context.Context) (context.Context, context.CancelFunc) {
+
+	if ctrl.DiagnosticsTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, ctrl.DiagnosticsTimeout)
 }
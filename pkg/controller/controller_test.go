@@ -4,43 +4,24 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	kubeassert "github.com/ish-xyz/go-kubetest/pkg/assert"
+	"github.com/ish-xyz/go-kubetest/pkg/chaos"
+	fakeclient "github.com/ish-xyz/go-kubetest/pkg/client/clientset/versioned/fake"
+	"github.com/ish-xyz/go-kubetest/pkg/diagnostics"
 	"github.com/ish-xyz/go-kubetest/pkg/loader"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 var ctxTest = context.TODO()
 
-func TestGetResourceDataFromPath(t *testing.T) {
-
-	gvkData, err := getResourceDataFromPath("v1:Namespace:namespace-1")
-
-	assert.Nil(t, err)
-	assert.Equal(t, gvkData["apiVersion"], "v1")
-	assert.Equal(t, gvkData["kind"], "Namespace")
-	assert.Equal(t, gvkData["namespace"], "")
-	assert.Equal(t, gvkData["name"], "namespace-1")
-
-}
-
-func TestGetResourceDataFromPathErrors(t *testing.T) {
-
-	_, err := getResourceDataFromPath("wrong resource path")
-
-	assert.NotNil(t, err)
-
-}
-
-func TestGetMaxRetriesErrors(t *testing.T) {
-
-	// Will default to 60s
-	limit := getMaxRetries("wrongString", 6)
-
-	assert.Equal(t, 10, limit)
-}
-
 func TestSetup(t *testing.T) {
 
 	// Prepare test data & mock
@@ -86,6 +67,8 @@ func TestSetupWithErrors(t *testing.T) {
 	errors := ctrl.Setup(ctxTest, objects)
 
 	assert.Len(t, errors, 1)
+	assert.Equal(t, "MockTest", errors[0].Name)
+	assert.Equal(t, "failed to create", errors[0].Message)
 	prvMock.AssertNumberOfCalls(t, testedMethod, 1)
 }
 
@@ -274,3 +257,1017 @@ func TestWaitForCreationErrors(t *testing.T) {
 	assert.False(t, result)
 	prvMock.AssertNumberOfCalls(t, testedMethod, 2)
 }
+
+func TestCreateTestResult(t *testing.T) {
+
+	// Prepare test data & mock
+	testedMethod := "CreateOrUpdate"
+	expected := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResult",
+			"metadata": map[string]interface{}{
+				"name":              "test-1",
+				"creationTimestamp": nil,
+			},
+			"spec": map[string]interface{}{
+				"result": false,
+				"assertions": map[string]interface{}{
+					"limits": false,
+				},
+				"messages": map[string]interface{}{
+					"limits": "expression is false for: default/unlimited",
+				},
+			},
+		},
+	}
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(testedMethod, context.TODO(), expected).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, nil)
+	err := ctrl.CreateTestResult(ctxTest, "test-1", TestResult{
+		Result:     false,
+		Assertions: map[string]bool{"limits": false},
+		Messages:   map[string]string{"limits": "expression is false for: default/unlimited"},
+	})
+
+	assert.Nil(t, err)
+	prvMock.AssertNumberOfCalls(t, testedMethod, 1)
+}
+
+func TestUpdateStatus(t *testing.T) {
+
+	// Prepare test data & client
+	ctrl := NewController(nil, nil, nil, nil)
+	ctrl.Client = fakeclient.NewSimpleClientset(&v1.TestDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "tests"},
+		Status:     v1.TestDefinitionStatus{NextRunTime: "2022-01-01T00:00:00Z"},
+	})
+	test := &loader.TestDefinition{Name: "test-1", LoadErrors: []string{"TestResource missing: not found"}}
+
+	// Run tests
+	err := ctrl.UpdateStatus(ctxTest, "tests", test, TestResult{Result: false}, time.Time{})
+	assert.Nil(t, err)
+
+	res, err := ctrl.Client.KubetestV1().TestDefinitions("tests").Get(ctxTest, "test-1", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "Failed", res.Status.LastResult)
+	assert.NotEmpty(t, res.Status.LastRunTime)
+	assert.Empty(t, res.Status.NextRunTime)
+	assert.Equal(t, []string{"TestResource missing: not found"}, res.Status.LoadErrors)
+
+	next := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	err = ctrl.UpdateStatus(ctxTest, "tests", &loader.TestDefinition{Name: "test-1"}, skippedResult("dependency failed"), next)
+	assert.Nil(t, err)
+
+	res, err = ctrl.Client.KubetestV1().TestDefinitions("tests").Get(ctxTest, "test-1", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "Skipped", res.Status.LastResult)
+	assert.Equal(t, "2022-01-01T12:00:00Z", res.Status.NextRunTime)
+	assert.Empty(t, res.Status.LoadErrors)
+}
+
+func TestUpdateStatusWithoutClient(t *testing.T) {
+
+	ctrl := NewController(nil, nil, nil, nil)
+	err := ctrl.UpdateStatus(ctxTest, "tests", &loader.TestDefinition{Name: "test-1"}, TestResult{Result: true}, time.Time{})
+
+	assert.Nil(t, err)
+}
+
+func TestRunTestCompositeAssertions(t *testing.T) {
+
+	// Prepare test data & mock, the sub-assertion of not and one of anyOf fail
+	noErrors := loader.Assertion{Name: "no-errors", Type: "expectedErrors"}
+	denied := loader.Assertion{Name: "denied", Type: "expectedErrors", Errors: []string{"denied"}}
+	test := &loader.TestDefinition{
+		Name: "composite",
+		Assert: []loader.Assertion{
+			{Name: "none", Type: "not", Assertions: []loader.Assertion{denied}},
+			{Name: "any", Type: "anyOf", Assertions: []loader.Assertion{denied, noErrors}},
+		},
+	}
+	prvMock := new(provisioner.ProvisionerMock)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.True(t, testResult.Result)
+	assert.True(t, testResult.Assertions["none"])
+	assert.True(t, testResult.Assertions["any"])
+	assert.NotContains(t, testResult.Assertions, "none.denied")
+	assert.Equal(t, map[string]bool{"none.denied": false, "any.denied": false, "any.no-errors": true}, testResult.SubAssertions)
+	assert.Empty(t, testResult.Warnings)
+	assert.False(t, failedAtSeverity(testResult, loader.SeverityInfo))
+}
+
+func TestFailedAtSeverity(t *testing.T) {
+
+	testResult := TestResult{
+		Result: true,
+		Assertions: map[string]bool{
+			"wait_for_creation": true,
+			"limits":            false,
+			"replicas":          true,
+		},
+		Severities: map[string]string{
+			"limits":   loader.SeverityWarning,
+			"replicas": loader.SeverityCritical,
+		},
+	}
+
+	assert.False(t, failedAtSeverity(testResult, ""))
+	assert.False(t, failedAtSeverity(testResult, loader.SeverityCritical))
+	assert.True(t, failedAtSeverity(testResult, loader.SeverityWarning))
+	assert.True(t, failedAtSeverity(testResult, loader.SeverityInfo))
+
+	// Entries without severity are critical
+	testResult.Assertions["wait_for_deletion"] = false
+	assert.True(t, failedAtSeverity(testResult, loader.SeverityCritical))
+}
+
+func newTestObject(apiVersion, kind, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+}
+
+func TestRunTestSteps(t *testing.T) {
+
+	// Prepare test data & mock
+	namespace := newTestObject("v1", "Namespace", "app")
+	appV1 := newTestObject("apps/v1", "Deployment", "app")
+	appV2 := newTestObject("apps/v1", "Deployment", "app")
+	config := newTestObject("v1", "ConfigMap", "app-config")
+	pod := newTestObject("v1", "Pod", "app-1")
+	patchData, _ := config.MarshalJSON()
+
+	test := &loader.TestDefinition{
+		Name:        "upgrade",
+		ObjectsList: []*unstructured.Unstructured{namespace, appV1},
+		Steps: []loader.Step{
+			{Name: "upgrade", ApplyObjects: []*unstructured.Unstructured{appV2}},
+			{Name: "flip-flag", PatchObjects: []*unstructured.Unstructured{config}},
+			{Name: "delete-pod", DeleteObjects: []*unstructured.Unstructured{pod}},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), namespace).Return(nil)
+	prvMock.On("CreateOrUpdate", context.TODO(), appV1).Return(nil)
+	prvMock.On("CreateOrUpdate", context.TODO(), appV2).Return(nil)
+	prvMock.On("Get", context.TODO(), config).Return(config, nil)
+	prvMock.On("Patch", context.TODO(), config, types.MergePatchType, patchData).Return(nil)
+	prvMock.On("Get", context.TODO(), pod).Return(pod, nil)
+	prvMock.On("CreateOrUpdate", context.TODO(), pod).Return(nil)
+	prvMock.On("Delete", context.TODO(), pod).Return(nil)
+	prvMock.On("Delete", context.TODO(), appV1).Return(nil)
+	prvMock.On("Delete", context.TODO(), namespace).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.True(t, testResult.Result)
+	assert.Equal(t, map[string]bool{"upgrade": true, "flip-flag": true, "delete-pod": true}, testResult.Steps)
+	prvMock.AssertNumberOfCalls(t, "Patch", 1)
+
+	// The pod wasn't created by the test, teardown recreates it
+	prvMock.AssertNumberOfCalls(t, "CreateOrUpdate", 4)
+	prvMock.AssertCalled(t, "CreateOrUpdate", context.TODO(), pod)
+
+	// The patched object isn't deleted, the Deployment is deleted once before its Namespace
+	prvMock.AssertNumberOfCalls(t, "Delete", 3)
+	deleted := []string{}
+	for _, call := range prvMock.Calls {
+		if call.Method == "Delete" {
+			deleted = append(deleted, call.Arguments[1].(*unstructured.Unstructured).GetKind())
+		}
+	}
+	assert.Equal(t, []string{"Pod", "Deployment", "Namespace"}, deleted)
+}
+
+func TestRunTestStepsFailure(t *testing.T) {
+
+	// Prepare test data & mock
+	app := newTestObject("apps/v1", "Deployment", "app")
+	test := &loader.TestDefinition{
+		Name: "recovery",
+		Steps: []loader.Step{
+			{
+				Name:         "apply",
+				ApplyObjects: []*unstructured.Unstructured{app},
+				Assert:       []loader.Assertion{{Name: "recovered", Type: "unknown"}},
+			},
+			{Name: "never-run"},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), app).Return(nil)
+	prvMock.On("Delete", context.TODO(), app).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.False(t, testResult.Result)
+	assert.Equal(t, map[string]bool{"apply": false}, testResult.Steps)
+	assert.False(t, testResult.Assertions["apply.recovered"])
+	assert.Equal(t, "unknown assertion type 'unknown'", testResult.Messages["apply.recovered"])
+
+	// Objects created by a failed step are still deleted
+	prvMock.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestRunTestStepsDeleteFailure(t *testing.T) {
+
+	// Prepare test data & mock
+	app := newTestObject("apps/v1", "Deployment", "app")
+	test := &loader.TestDefinition{
+		Name:        "removal",
+		ObjectsList: []*unstructured.Unstructured{app},
+		Steps: []loader.Step{
+			{Name: "remove", DeleteObjects: []*unstructured.Unstructured{app}},
+			{Name: "never-run"},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), app).Return(nil)
+	prvMock.On("Delete", context.TODO(), app).Return(errors.New("forbidden")).Once()
+	prvMock.On("Delete", context.TODO(), app).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.False(t, testResult.Result)
+	assert.Equal(t, map[string]bool{"remove": false}, testResult.Steps)
+	assert.False(t, testResult.Assertions["remove.delete"])
+	assert.Contains(t, testResult.Messages["remove.delete"], "forbidden")
+
+	// The object that couldn't be deleted is still deleted by teardown
+	prvMock.AssertNumberOfCalls(t, "Delete", 2)
+}
+
+func TestRunTestChaosRestore(t *testing.T) {
+
+	// Prepare test data & mock
+	app := newTestObject("apps/v1", "Deployment", "echo")
+	app.SetNamespace("app")
+	deployment := app.DeepCopy()
+	unstructured.SetNestedField(deployment.Object, int64(2), "spec", "replicas")
+
+	scale := loader.ChaosAction{Name: "scale-to-zero", Type: "scaleDeployment", Namespace: "app", Target: "echo"}
+	test := &loader.TestDefinition{
+		Name:        "recovery",
+		ObjectsList: []*unstructured.Unstructured{app},
+		Steps:       []loader.Step{{Name: "outage", Chaos: []loader.ChaosAction{scale}}},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), app).Return(nil)
+	prvMock.On("Get", context.TODO(), mock.Anything).Return(deployment, nil)
+	prvMock.On("Patch", context.TODO(), deployment, types.MergePatchType, mock.Anything).Return(nil)
+	prvMock.On("Delete", context.TODO(), app).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	ctrl.Chaos = chaos.NewRunner(prvMock, []string{"app"}, 1, false)
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.True(t, testResult.Result)
+	assert.True(t, testResult.Assertions["outage.scale-to-zero"])
+
+	// The deployment is scaled back before being deleted
+	methods := []string{}
+	for _, call := range prvMock.Calls {
+		methods = append(methods, call.Method)
+	}
+	assert.Equal(t, []string{"CreateOrUpdate", "Get", "Patch", "Patch", "Delete"}, methods)
+	assert.Equal(t, []byte(`{"spec":{"replicas":2}}`), prvMock.Calls[3].Arguments[3])
+}
+
+func TestRunTestChaosDisabled(t *testing.T) {
+
+	test := &loader.TestDefinition{
+		Name: "recovery",
+		Steps: []loader.Step{
+			{Name: "outage", Chaos: []loader.ChaosAction{{Name: "kill", Type: "deletePods", Namespace: "app"}}},
+		},
+	}
+
+	// Run tests
+	prvMock := new(provisioner.ProvisionerMock)
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.False(t, testResult.Result)
+	assert.Equal(t, "chaos actions are disabled", testResult.Messages["outage.kill"])
+	assert.Empty(t, prvMock.Calls)
+}
+
+func TestRunTestObjectActions(t *testing.T) {
+
+	// Prepare test data & mock
+	flags := newTestObject("v1", "ConfigMap", "flags")
+	flags.SetNamespace("app")
+	original := flags.DeepCopy()
+	original.SetResourceVersion("1")
+	unstructured.SetNestedField(original.Object, "false", "data", "enabled")
+	patched := original.DeepCopy()
+	patched.SetResourceVersion("2")
+	unstructured.SetNestedField(patched.Object, "true", "data", "enabled")
+
+	class := newTestObject("storage.k8s.io/v1", "StorageClass", "standard")
+	liveClass := class.DeepCopy()
+	liveClass.SetUID("1234")
+	liveClass.Object["provisioner"] = "example.com/disk"
+
+	test := &loader.TestDefinition{
+		Name: "feature-flag",
+		Steps: []loader.Step{
+			{
+				Name: "enable",
+				Actions: []loader.ObjectAction{
+					{Name: "flip-flag", Type: "patch", Resource: "v1:ConfigMap:app:flags", Patch: "data:\n  enabled: 'true'"},
+					{Name: "remove-class", Type: "delete", Resource: "storage.k8s.io/v1:StorageClass:standard"},
+				},
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", context.TODO(), flags).Return(original, nil).Once()
+	prvMock.On("Get", context.TODO(), original).Return(patched, nil).Once()
+	prvMock.On("Patch", context.TODO(), flags, types.MergePatchType, []byte(`{"data":{"enabled":"true"}}`)).Return(nil)
+	prvMock.On("Patch", context.TODO(), original, types.MergePatchType, []byte(`{"data":{"enabled":"false"}}`)).Return(nil)
+	prvMock.On("Get", context.TODO(), class).Return(liveClass, nil)
+	prvMock.On("Delete", context.TODO(), class).Return(nil)
+	prvMock.On("CreateOrUpdate", context.TODO(), mock.Anything).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.True(t, testResult.Result)
+	assert.True(t, testResult.Assertions["enable.flip-flag"])
+	assert.True(t, testResult.Assertions["enable.remove-class"])
+
+	// Teardown recreates the StorageClass without server fields, then reverts the ConfigMap
+	prvMock.AssertNumberOfCalls(t, "Patch", 2)
+	prvMock.AssertNumberOfCalls(t, "Delete", 1)
+	created := prvMock.Calls[len(prvMock.Calls)-3].Arguments[1].(*unstructured.Unstructured)
+	assert.Equal(t, "", string(created.GetUID()))
+	assert.Equal(t, "example.com/disk", created.Object["provisioner"])
+}
+
+func TestKeepResources(t *testing.T) {
+
+	ctrl := NewController(nil, nil, nil, nil)
+	test := &loader.TestDefinition{Name: "test-1"}
+
+	assert.False(t, ctrl.keepResources(test, false))
+
+	ctrl.KeepOnFailure = true
+	assert.True(t, ctrl.keepResources(test, false))
+	assert.False(t, ctrl.keepResources(test, true))
+
+	// The policy of the test takes precedence over the controller one
+	ctrl.KeepOnFailure = false
+	ctrl.TeardownPolicy = loader.TeardownNever
+	test.TeardownPolicy = loader.TeardownOnSuccess
+	assert.True(t, ctrl.keepResources(test, false))
+	assert.False(t, ctrl.keepResources(test, true))
+
+	test.TeardownPolicy = ""
+	assert.True(t, ctrl.keepResources(test, true))
+}
+
+func TestRunTestRetainOnFailure(t *testing.T) {
+
+	// Prepare test data & mock
+	namespace := newTestObject("v1", "Namespace", "debug-me")
+	test := &loader.TestDefinition{
+		Name:        "broken",
+		ObjectsList: []*unstructured.Unstructured{namespace},
+		Assert:      []loader.Assertion{{Name: "broken", Type: "unknown"}},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), namespace).Return(nil)
+	prvMock.On("Delete", context.TODO(), namespace).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	ctrl.KeepOnFailure = true
+	ctrl.RetentionTTL = time.Hour
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.False(t, testResult.Result)
+	assert.Equal(t, []string{"v1:Namespace:debug-me"}, testResult.Retained)
+	assert.NotEmpty(t, testResult.RetainedUntil)
+	prvMock.AssertNotCalled(t, "Delete", context.TODO(), namespace)
+
+	// Not expired yet
+	ctrl.CleanupRetained(ctxTest, time.Now())
+	prvMock.AssertNotCalled(t, "Delete", context.TODO(), namespace)
+
+	ctrl.CleanupRetained(ctxTest, time.Now().Add(2*time.Hour))
+	prvMock.AssertNumberOfCalls(t, "Delete", 1)
+
+	// Nothing left to clean
+	ctrl.CleanupRetained(ctxTest, time.Now().Add(2*time.Hour))
+	prvMock.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestRunTestReleasesRetained(t *testing.T) {
+
+	// Prepare test data & mock
+	namespace := newTestObject("v1", "Namespace", "debug-me")
+	test := &loader.TestDefinition{
+		Name:           "kept",
+		ObjectsList:    []*unstructured.Unstructured{namespace},
+		TeardownPolicy: loader.TeardownNever,
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), namespace).Return(nil)
+	prvMock.On("Delete", context.TODO(), namespace).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.True(t, testResult.Result)
+	assert.Empty(t, testResult.RetainedUntil)
+	prvMock.AssertNumberOfCalls(t, "Delete", 0)
+
+	// The resources of the previous run are deleted before running the test again
+	ctrl.RunTest(ctxTest, test)
+	prvMock.AssertNumberOfCalls(t, "Delete", 1)
+	prvMock.AssertNumberOfCalls(t, "CreateOrUpdate", 2)
+}
+
+func TestRunTestRetainRevertsPatches(t *testing.T) {
+
+	// Prepare test data & mock
+	namespace := newTestObject("v1", "Namespace", "debug-me")
+	flags := newTestObject("v1", "ConfigMap", "flags")
+	flags.SetNamespace("app")
+	original := flags.DeepCopy()
+	unstructured.SetNestedField(original.Object, "false", "data", "enabled")
+	patched := original.DeepCopy()
+	unstructured.SetNestedField(patched.Object, "true", "data", "enabled")
+
+	test := &loader.TestDefinition{
+		Name:           "feature-flag",
+		ObjectsList:    []*unstructured.Unstructured{namespace},
+		TeardownPolicy: loader.TeardownNever,
+		Steps: []loader.Step{
+			{
+				Name: "enable",
+				Actions: []loader.ObjectAction{
+					{Name: "flip-flag", Type: "patch", Resource: "v1:ConfigMap:app:flags", Patch: "data:\n  enabled: 'true'"},
+				},
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), namespace).Return(nil)
+	prvMock.On("Get", context.TODO(), flags).Return(original, nil).Once()
+	prvMock.On("Get", context.TODO(), original).Return(patched, nil).Once()
+	prvMock.On("Patch", context.TODO(), flags, types.MergePatchType, []byte(`{"data":{"enabled":"true"}}`)).Return(nil)
+	prvMock.On("Patch", context.TODO(), original, types.MergePatchType, []byte(`{"data":{"enabled":"false"}}`)).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	// The namespace is kept, the patch is reverted anyway
+	assert.True(t, testResult.Result)
+	assert.Equal(t, []string{"v1:Namespace:debug-me"}, testResult.Retained)
+	prvMock.AssertNumberOfCalls(t, "Patch", 2)
+	prvMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestRestoreStateRetained(t *testing.T) {
+
+	// Prepare test data & mock, the TestResults of the previous runs
+	newResult := func(name string, spec map[string]interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResult",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       spec,
+		}}
+	}
+	results := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		newResult("expired", map[string]interface{}{
+			"result":        false,
+			"assertions":    map[string]interface{}{},
+			"retained":      []interface{}{"v1:Namespace:expired", "apps/v1:Deployment:expired:app"},
+			"retainedUntil": "2022-01-10T12:00:00Z",
+		}),
+		newResult("no-ttl", map[string]interface{}{
+			"result":     false,
+			"assertions": map[string]interface{}{},
+			"retained":   []interface{}{"v1:Namespace:no-ttl"},
+		}),
+		newResult("cleaned", map[string]interface{}{
+			"result":     true,
+			"assertions": map[string]interface{}{},
+		}),
+	}}
+
+	expiredNS := newTestObject("v1", "Namespace", "expired")
+	deployment := newTestObject("apps/v1", "Deployment", "app")
+	deployment.SetNamespace("expired")
+	noTTL := newTestObject("v1", "Namespace", "no-ttl")
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{"apiVersion": "go-kubetest.io/v1", "kind": "TestResult", "namespace": ""},
+		map[string]interface{}{},
+	).Return(results, nil)
+	prvMock.On("Delete", context.TODO(), mock.Anything).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, nil)
+	err := ctrl.RestoreState(ctxTest)
+	assert.Nil(t, err)
+
+	ctrl.CleanupRetained(ctxTest, time.Date(2022, 1, 10, 13, 0, 0, 0, time.UTC))
+
+	// Only the expired resources are deleted
+	prvMock.AssertNumberOfCalls(t, "Delete", 2)
+	prvMock.AssertCalled(t, "Delete", context.TODO(), deployment)
+	prvMock.AssertCalled(t, "Delete", context.TODO(), expiredNS)
+	prvMock.AssertNotCalled(t, "Delete", context.TODO(), noTTL)
+
+	// The resources without TTL are deleted before the test runs again
+	ctrl.releaseRetained(ctxTest, "no-ttl")
+	prvMock.AssertCalled(t, "Delete", context.TODO(), noTTL)
+}
+
+func TestRunTestDiagnostics(t *testing.T) {
+
+	// Prepare test data & mock
+	test := &loader.TestDefinition{
+		Name:   "broken",
+		Assert: []loader.Assertion{{Name: "broken", Type: "unknown"}},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{"apiVersion": "v1", "kind": "Node", "namespace": ""},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{}, nil)
+
+	// Run tests, a summary is attached without diagnostics directory
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	ctrl.Diagnostics = diagnostics.NewCollector(prvMock, "", 0, 0)
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.False(t, testResult.Result)
+	assert.Contains(t, testResult.Diagnostics, "nodes.txt")
+	assert.Empty(t, testResult.DiagnosticsPath)
+
+	ctrl.Diagnostics.Dir = t.TempDir()
+	testResult = ctrl.RunTest(ctxTest, test)
+
+	assert.Empty(t, testResult.Diagnostics)
+	assert.DirExists(t, testResult.DiagnosticsPath)
+}
+
+func TestRunTestDiagnosticsSubAssertions(t *testing.T) {
+
+	// Prepare test data & mock, the pods matched by the failed sub-assertion are collected
+	pod := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web-1", "namespace": "app"},
+	}}
+	test := &loader.TestDefinition{
+		Name: "web",
+		Assert: []loader.Assertion{
+			{Name: "ready", Type: "allOf", Assertions: []loader.Assertion{
+				{Name: "replicas", Type: "expectedResources", Resource: "v1:Pod:app", Selectors: map[string]interface{}{}, Count: 2, Timeout: "2s"},
+			}},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		mock.Anything,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "app"},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{pod}}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{"apiVersion": "v1", "kind": "Event", "namespace": "app"},
+		map[string]interface{}{"involvedObject.kind": "Pod", "involvedObject.name": "web-1"},
+	).Return(&unstructured.UnstructuredList{}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{"apiVersion": "v1", "kind": "Node", "namespace": ""},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{}, nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	ctrl.Diagnostics = diagnostics.NewCollector(prvMock, "", 0, 0)
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.False(t, testResult.Result)
+	assert.False(t, testResult.SubAssertions["ready.replicas"])
+	assert.Contains(t, testResult.Diagnostics, "objects/pod_app_web-1.yaml")
+}
+
+func TestSortByKind(t *testing.T) {
+
+	objects := []*unstructured.Unstructured{
+		newTestObject("example.com/v1", "Widget", "widget-1"),
+		newTestObject("apps/v1", "Deployment", "app"),
+		newTestObject("v1", "ConfigMap", "config"),
+		newTestObject("rbac.authorization.k8s.io/v1", "RoleBinding", "app"),
+		newTestObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.com"),
+		newTestObject("v1", "Namespace", "app"),
+		newTestObject("example.com/v1", "Widget", "widget-2"),
+	}
+
+	kinds := []string{}
+	names := []string{}
+	for _, obj := range sortByKind(objects) {
+		kinds = append(kinds, obj.GetKind())
+		names = append(names, obj.GetName())
+	}
+
+	assert.Equal(t, []string{"Namespace", "CustomResourceDefinition", "RoleBinding", "ConfigMap", "Deployment", "Widget", "Widget"}, kinds)
+	assert.Equal(t, []string{"widget-1", "widget-2"}, names[5:])
+
+	// The input is left untouched
+	assert.Equal(t, "Widget", objects[0].GetKind())
+}
+
+func TestSetupWaitsForCRDs(t *testing.T) {
+
+	// Prepare test data & mock
+	widget := newTestObject("example.com/v1", "Widget", "widget-1")
+	crd := newTestObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.com")
+	established := crd.DeepCopy()
+	unstructured.SetNestedSlice(established.Object, []interface{}{
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", context.TODO(), mock.Anything).Return(nil)
+	prvMock.On("Get", context.TODO(), crd).Return(crd, nil).Once()
+	prvMock.On("Get", context.TODO(), crd).Return(established, nil).Once()
+	prvMock.On("ResetMapper").Return()
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, nil)
+	errors := ctrl.Setup(ctxTest, []*unstructured.Unstructured{widget, crd})
+
+	assert.Len(t, errors, 0)
+	methods := []string{}
+	for _, call := range prvMock.Calls {
+		methods = append(methods, call.Method)
+	}
+	assert.Equal(t, []string{"CreateOrUpdate", "Get", "Get", "ResetMapper", "CreateOrUpdate"}, methods)
+	assert.Equal(t, widget, prvMock.Calls[4].Arguments[1])
+}
+
+func TestTeardownOrder(t *testing.T) {
+
+	// Prepare test data & mock
+	objects := []*unstructured.Unstructured{
+		newTestObject("apps/v1", "Deployment", "app"),
+		newTestObject("v1", "Namespace", "app"),
+		newTestObject("example.com/v1", "Widget", "widget-1"),
+	}
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Delete", context.TODO(), mock.Anything).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, nil)
+	ctrl.Teardown(ctxTest, objects)
+
+	kinds := []string{}
+	for _, call := range prvMock.Calls {
+		kinds = append(kinds, call.Arguments[1].(*unstructured.Unstructured).GetKind())
+	}
+	assert.Equal(t, []string{"Widget", "Deployment", "Namespace"}, kinds)
+}
+
+func TestRunTestsDependencies(t *testing.T) {
+
+	// Prepare test data & mock
+	tests := []*loader.TestDefinition{
+		{Name: "routing", DependsOn: []string{"ingress"}},
+		{Name: "ingress", Assert: []loader.Assertion{{Name: "broken", Type: "unknown"}}},
+		{Name: "tls", DependsOn: []string{"routing"}},
+		{Name: "storage"},
+		{Name: "database", DependsOn: []string{"storage"}},
+		{Name: "cache", DependsOn: []string{"not-loaded"}},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	ctrl.Concurrency = 3
+
+	var order []string
+	results := map[string]TestResult{}
+	ctrl.RunTests(ctxTest, tests, func(test *loader.TestDefinition, testResult TestResult) {
+		order = append(order, test.Name)
+		results[test.Name] = testResult
+	})
+
+	assert.Len(t, order, len(tests))
+	assert.Less(t, indexOf(order, "storage"), indexOf(order, "database"))
+	assert.Less(t, indexOf(order, "ingress"), indexOf(order, "routing"))
+	assert.Less(t, indexOf(order, "routing"), indexOf(order, "tls"))
+
+	assert.False(t, results["ingress"].Result)
+	assert.False(t, results["ingress"].Skipped)
+	assert.True(t, results["storage"].Result)
+	assert.True(t, results["database"].Result)
+
+	assert.True(t, results["routing"].Skipped)
+	assert.Equal(t, "dependency 'ingress' didn't pass", results["routing"].SkipReason)
+	assert.True(t, results["tls"].Skipped)
+	assert.Equal(t, "dependency 'routing' didn't pass", results["tls"].SkipReason)
+	assert.True(t, results["cache"].Skipped)
+	assert.Equal(t, "dependency 'not-loaded' not found", results["cache"].SkipReason)
+	assert.True(t, failedAtSeverity(results["cache"], loader.SeverityInfo))
+}
+
+func TestRunTestsLoadErrors(t *testing.T) {
+
+	// Prepare test data & mock
+	tests := []*loader.TestDefinition{
+		{Name: "ingress", LoadErrors: []string{"TestResource ingress-manifests: not found", "negative retries"}},
+		{Name: "routing", DependsOn: []string{"ingress"}},
+	}
+	prvMock := new(provisioner.ProvisionerMock)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+
+	results := map[string]TestResult{}
+	ctrl.RunTests(ctxTest, tests, func(test *loader.TestDefinition, testResult TestResult) {
+		results[test.Name] = testResult
+	})
+
+	assert.False(t, results["ingress"].Result)
+	assert.False(t, results["ingress"].Skipped)
+	assert.Equal(t, map[string]bool{"load": false}, results["ingress"].Assertions)
+	assert.Equal(t, "TestResource ingress-manifests: not found; negative retries", results["ingress"].Messages["load"])
+	assert.Equal(t, tests[0].LoadErrors, results["ingress"].LoadErrors)
+	assert.True(t, results["routing"].Skipped)
+	prvMock.AssertNotCalled(t, "CreateOrUpdate", mock.Anything, mock.Anything)
+}
+
+func indexOf(list []string, value string) int {
+	for i, v := range list {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRunTestsRetries(t *testing.T) {
+
+	// Prepare test data & mock, the check fails on the first run only
+	runs := 0
+	asrt := kubeassert.NewAssert(new(provisioner.ProvisionerMock))
+	asrt.Registry.Register("second-time-lucky", kubeassert.CheckerFunc(
+		func(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) kubeassert.Result {
+			runs++
+			return kubeassert.Result{Passed: runs > 1}
+		},
+	))
+
+	tests := []*loader.TestDefinition{
+		{Name: "lucky", Retries: 2, Assert: []loader.Assertion{{Name: "lucky", Type: "second-time-lucky"}}},
+		{Name: "broken", Retries: 1, Assert: []loader.Assertion{{Name: "broken", Type: "unknown"}}},
+	}
+
+	// Run tests
+	ctrl := NewController(nil, nil, nil, asrt)
+	ctrl.RetryBackoff = time.Millisecond
+
+	results := map[string]TestResult{}
+	ctrl.RunTests(ctxTest, tests, func(test *loader.TestDefinition, testResult TestResult) {
+		results[test.Name] = testResult
+	})
+
+	assert.True(t, results["lucky"].Result)
+	assert.Equal(t, int32(2), results["lucky"].Attempts)
+	assert.True(t, results["lucky"].Flaky)
+	assert.Equal(t, 1.0, results["lucky"].Flakiness)
+
+	assert.False(t, results["broken"].Result)
+	assert.Equal(t, int32(2), results["broken"].Attempts)
+	assert.False(t, results["broken"].Flaky)
+}
+
+func TestFlakiness(t *testing.T) {
+
+	pass := runOutcome{Passed: true, Attempts: 1}
+	fail := runOutcome{Passed: false, Attempts: 1}
+	passOnRetry := runOutcome{Passed: true, Attempts: 3}
+
+	assert.Equal(t, 0.0, flakiness(nil))
+	assert.Equal(t, 0.0, flakiness([]runOutcome{pass, pass, fail, fail, fail}))
+	assert.Equal(t, 0.0, flakiness([]runOutcome{fail, fail, pass, pass}))
+	assert.Equal(t, 0.25, flakiness([]runOutcome{pass, fail, pass, pass}))
+	assert.Equal(t, 0.5, flakiness([]runOutcome{pass, fail, pass, fail}))
+	assert.Equal(t, 0.5, flakiness([]runOutcome{pass, passOnRetry, pass, passOnRetry}))
+}
+
+func TestRecordRunHistory(t *testing.T) {
+
+	ctrl := NewController(nil, nil, nil, nil)
+
+	testResult := ctrl.recordRun("lucky", TestResult{Result: true, Attempts: 2})
+	assert.True(t, testResult.Flaky)
+
+	// Skipped runs don't change the history
+	testResult = ctrl.recordRun("lucky", skippedResult("dependency cycle"))
+	assert.Equal(t, 1.0, testResult.Flakiness)
+
+	// Old runs leave the history
+	for i := 0; i < historySize; i++ {
+		testResult = ctrl.recordRun("lucky", TestResult{Result: true, Attempts: 1})
+	}
+	assert.False(t, testResult.Flaky)
+	assert.Len(t, ctrl.history["lucky"], historySize)
+	assert.Equal(t, ctrl.history["lucky"], testResult.History)
+}
+
+func TestRestoreStateHistory(t *testing.T) {
+
+	// Prepare test data & mock, the test passed on retry in the previous run
+	results := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResult",
+			"metadata":   map[string]interface{}{"name": "lucky"},
+			"spec": map[string]interface{}{
+				"result":     true,
+				"assertions": map[string]interface{}{},
+				"history": []interface{}{
+					map[string]interface{}{"passed": true, "attempts": int64(1)},
+					map[string]interface{}{"passed": true, "attempts": int64(2)},
+				},
+			},
+		}},
+	}}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("ListWithSelectors", context.TODO(), mock.Anything, mock.Anything).Return(results, nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, nil)
+	err := ctrl.RestoreState(ctxTest)
+	assert.Nil(t, err)
+
+	testResult := ctrl.recordRun("lucky", TestResult{Result: true, Attempts: 1})
+
+	assert.Len(t, testResult.History, 3)
+	assert.InDelta(t, 1.0/3, testResult.Flakiness, 0.001)
+	assert.True(t, testResult.Flaky)
+}
+
+func TestRunTestTimeout(t *testing.T) {
+
+	// Prepare test data & mock, the check hangs until the test times out
+	namespace := newTestObject("v1", "Namespace", "hung")
+	test := &loader.TestDefinition{
+		Name:        "hung",
+		Timeout:     "100ms",
+		ObjectsList: []*unstructured.Unstructured{namespace},
+		Assert:      []loader.Assertion{{Name: "hung", Type: "hang"}},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", mock.Anything, namespace).Return(nil)
+	prvMock.On("Delete", mock.Anything, namespace).Return(nil)
+
+	asrt := kubeassert.NewAssert(prvMock)
+	asrt.Registry.Register("hang", kubeassert.CheckerFunc(
+		func(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) kubeassert.Result {
+			<-ctx.Done()
+			return kubeassert.Result{Message: ctx.Err().Error()}
+		},
+	))
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, asrt)
+	ctrl.TeardownTimeout = time.Minute
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	assert.False(t, testResult.Result)
+	assert.False(t, testResult.Assertions["timeout"])
+	assert.Equal(t, "test didn't finish within 100ms", testResult.Messages["timeout"])
+	assert.True(t, testResult.Assertions["wait_for_deletion"])
+
+	// Teardown runs with its own budget after the timeout
+	prvMock.AssertCalled(t, "Delete", mock.Anything, namespace)
+}
+
+func TestRunTestTeardownBudget(t *testing.T) {
+
+	// Prepare test data & mock, the assertion outlasts the teardown budget
+	namespace := newTestObject("v1", "Namespace", "slow")
+	test := &loader.TestDefinition{
+		Name:        "slow",
+		ObjectsList: []*unstructured.Unstructured{namespace},
+		Assert:      []loader.Assertion{{Name: "slow", Type: "slow"}},
+	}
+
+	var deleteErr error
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", mock.Anything, namespace).Return(nil)
+	prvMock.On("Delete", mock.Anything, namespace).Return(nil).Run(func(args mock.Arguments) {
+		deleteErr = args.Get(0).(context.Context).Err()
+	})
+
+	asrt := kubeassert.NewAssert(prvMock)
+	asrt.Registry.Register("slow", kubeassert.CheckerFunc(
+		func(ctx context.Context, prv provisioner.Provisioner, assertion loader.Assertion) kubeassert.Result {
+			time.Sleep(300 * time.Millisecond)
+			return kubeassert.Result{Passed: true}
+		},
+	))
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, asrt)
+	ctrl.TeardownTimeout = 100 * time.Millisecond
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	// The budget starts with the teardown, not with the test
+	assert.True(t, testResult.Result)
+	prvMock.AssertCalled(t, "Delete", mock.Anything, namespace)
+	assert.Nil(t, deleteErr)
+}
+
+func TestRunTestSlowDiagnostics(t *testing.T) {
+
+	// Prepare test data & mock, collecting the diagnostics outlasts the teardown budget
+	namespace := newTestObject("v1", "Namespace", "broken")
+	test := &loader.TestDefinition{
+		Name:        "broken",
+		ObjectsList: []*unstructured.Unstructured{namespace},
+		Assert:      []loader.Assertion{{Name: "broken", Type: "unknown"}},
+	}
+
+	var deleteErr error
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", mock.Anything, namespace).Return(nil)
+	prvMock.On("Get", mock.Anything, namespace).Return((*unstructured.Unstructured)(nil), context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	})
+	prvMock.On("ListWithSelectors", mock.Anything, mock.Anything, mock.Anything).Return((*unstructured.UnstructuredList)(nil), context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	})
+	prvMock.On("Delete", mock.Anything, namespace).Return(nil).Run(func(args mock.Arguments) {
+		deleteErr = args.Get(0).(context.Context).Err()
+	})
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	ctrl.Diagnostics = diagnostics.NewCollector(prvMock, "", 0, 0)
+	ctrl.DiagnosticsTimeout = 200 * time.Millisecond
+	ctrl.TeardownTimeout = 100 * time.Millisecond
+	testResult := ctrl.RunTest(ctxTest, test)
+
+	// The diagnostics ran out of time, the cleanup still had its whole budget
+	assert.False(t, testResult.Result)
+	assert.Contains(t, testResult.Diagnostics, "errors.txt")
+	assert.True(t, testResult.Assertions["wait_for_deletion"])
+	prvMock.AssertCalled(t, "Delete", mock.Anything, namespace)
+	assert.Nil(t, deleteErr)
+}
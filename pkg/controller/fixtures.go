@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/sirupsen/logrus"
+)
+
+// Count the tests of an execution using each fixture, a fixture
+// is torn down once all of them have released it
+func (ctrl *Controller) prepareFixtures(tests []*loader.TestDefinition) {
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	ctrl.fixtures = map[string]*fixtureState{}
+	for _, test := range tests {
+		for _, fixture := range test.FixturesList {
+			state, ok := ctrl.fixtures[fixture.Name]
+			if !ok {
+				state = &fixtureState{fixture: fixture}
+				ctrl.fixtures[fixture.Name] = state
+			}
+			state.users++
+		}
+	}
+}
+
+// Run a test once its fixtures are set up, then release them
+func (ctrl *Controller) runWithFixtures(ctx context.Context, test *loader.TestDefinition) TestResult {
+
+	defer ctrl.releaseFixtures(ctx, test)
+
+	failures := ctrl.acquireFixtures(ctx, test)
+	if len(failures) > 0 {
+		logrus.Errorf("Fixture/s of test '%s' couldn't be set up, skipping test", test.Name)
+		testResult := TestResult{
+			Assertions: map[string]bool{},
+			Messages:   map[string]string{},
+		}
+		for name, message := range failures {
+			testResult.Assertions["fixture."+name] = false
+			testResult.Messages["fixture."+name] = message
+		}
+		return testResult
+	}
+
+	return ctrl.runWithRetries(ctx, test)
+}
+
+// Set up the fixtures of a test, unless a previous test already did,
+// and return why the ones that aren't ready couldn't be set up
+func (ctrl *Controller) acquireFixtures(ctx context.Context, test *loader.TestDefinition) map[string]string {
+
+	failures := map[string]string{}
+	for _, fixture := range test.FixturesList {
+		state := ctrl.fixtureState(fixture)
+
+		// Tests running in parallel wait for the first one to set the fixture up
+		state.mu.Lock()
+		if state.run == nil {
+			logrus.Infof("Setting up fixture '%s'", fixture.Name)
+			state.run = &testRun{created: newCreatedObjects()}
+
+			var messages []string
+			for _, err := range ctrl.Setup(ctx, fixture.ObjectsList) {
+				messages = append(messages, err.Error())
+			}
+			state.run.created.add(fixture.ObjectsList...)
+			if !ctrl.WaitForCreation(ctx, fixture.Setup.WaitFor) {
+				messages = append(messages, "error while waiting for resource/s to be created")
+			}
+			state.failure = strings.Join(messages, "\n")
+		}
+		if state.failure != "" {
+			failures[fixture.Name] = state.failure
+		}
+		state.mu.Unlock()
+	}
+
+	return failures
+}
+
+// Release the fixtures of a test, the last test using a fixture tears it down.
+// Skipped tests release their fixtures as well
+func (ctrl *Controller) releaseFixtures(ctx context.Context, test *loader.TestDefinition) {
+
+	for _, fixture := range test.FixturesList {
+		state := ctrl.fixtureState(fixture)
+
+		ctrl.mu.Lock()
+		state.users--
+		last := state.users <= 0
+		ctrl.mu.Unlock()
+		if !last {
+			continue
+		}
+
+		state.mu.Lock()
+		if state.run != nil {
+			logrus.Infof("Tearing down fixture '%s'", fixture.Name)
+			teardownCtx, cancel := ctrl.teardownContext(ctx)
+			ctrl.cleanup(teardownCtx, state.run)
+			if !ctrl.WaitForDeletion(teardownCtx, fixture.Teardown.WaitFor) {
+				logrus.Errorf("Error while waiting for resource/s to be deleted, fixture: '%s'", fixture.Name)
+			}
+			cancel()
+			state.run = nil
+		}
+		state.mu.Unlock()
+	}
+}
+
+// Return the state of a fixture, fixtures of tests run outside
+// of RunTests are torn down after the test
+func (ctrl *Controller) fixtureState(fixture *loader.Fixture) *fixtureState {
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	if ctrl.fixtures == nil {
+		ctrl.fixtures = map[string]*fixtureState{}
+	}
+	state, ok := ctrl.fixtures[fixture.Name]
+	if !ok {
+		state = &fixtureState{fixture: fixture, users: 1}
+		ctrl.fixtures[fixture.Name] = state
+	}
+	return state
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	kubeassert "github.com/ish-xyz/go-kubetest/pkg/assert"
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRunTestsSharedFixture(t *testing.T) {
+
+	// Prepare test data & mock
+	operator := newTestObject("v1", "Namespace", "operator")
+	fixture := &loader.Fixture{Name: "operator", ObjectsList: []*unstructured.Unstructured{operator}}
+
+	tests := []*loader.TestDefinition{
+		{Name: "webhook", FixturesList: []*loader.Fixture{fixture}},
+		{Name: "reconcile", FixturesList: []*loader.Fixture{fixture}},
+		{Name: "upgrade", FixturesList: []*loader.Fixture{fixture}, DependsOn: []string{"webhook"}},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", mock.Anything, operator).Return(nil)
+	prvMock.On("Delete", mock.Anything, operator).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+	ctrl.Concurrency = 2
+
+	reported := 0
+	ctrl.RunTests(ctxTest, tests, func(test *loader.TestDefinition, testResult TestResult) {
+		assert.True(t, testResult.Result)
+		reported++
+	})
+
+	// Set up once and torn down after the last test only
+	assert.Equal(t, 3, reported)
+	prvMock.AssertNumberOfCalls(t, "CreateOrUpdate", 1)
+	prvMock.AssertNumberOfCalls(t, "Delete", 1)
+	assert.Empty(t, ctrl.fixtures["operator"].users)
+}
+
+func TestRunTestsFixtureFailure(t *testing.T) {
+
+	// Prepare test data & mock
+	operator := newTestObject("v1", "Namespace", "operator")
+	fixture := &loader.Fixture{Name: "operator", ObjectsList: []*unstructured.Unstructured{operator}}
+
+	tests := []*loader.TestDefinition{
+		{Name: "webhook", FixturesList: []*loader.Fixture{fixture}},
+		{Name: "reconcile", FixturesList: []*loader.Fixture{fixture}},
+		{Name: "no-fixture"},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("CreateOrUpdate", mock.Anything, operator).Return(errors.New("forbidden"))
+	prvMock.On("Delete", mock.Anything, operator).Return(nil)
+
+	// Run tests
+	ctrl := NewController(nil, prvMock, nil, kubeassert.NewAssert(prvMock))
+
+	results := map[string]TestResult{}
+	ctrl.RunTests(ctxTest, tests, func(test *loader.TestDefinition, testResult TestResult) {
+		results[test.Name] = testResult
+	})
+
+	assert.False(t, results["webhook"].Result)
+	assert.False(t, results["webhook"].Assertions["fixture.operator"])
+	assert.Contains(t, results["webhook"].Messages["fixture.operator"], "forbidden")
+	assert.False(t, results["reconcile"].Result)
+	assert.True(t, results["no-fixture"].Result)
+
+	// The failed setup isn't retried by the second test
+	prvMock.AssertNumberOfCalls(t, "CreateOrUpdate", 1)
+	prvMock.AssertNumberOfCalls(t, "Delete", 1)
+}
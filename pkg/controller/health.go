@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Paths of the health checks
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+// Add the liveness and readiness checks to a mux
+func (ctrl *Controller) RegisterHealthChecks(mux *http.ServeMux) {
+	mux.HandleFunc(HealthzPath, ctrl.Healthz)
+	mux.HandleFunc(ReadyzPath, ctrl.Readyz)
+}
+
+// Healthz is the liveness check, it fails once the loader has
+// been failing for longer than UnhealthyAfter
+func (ctrl *Controller) Healthz(w http.ResponseWriter, r *http.Request) {
+
+	ctrl.mu.Lock()
+	health := ctrl.health
+	ctrl.mu.Unlock()
+
+	if ctrl.UnhealthyAfter > 0 && health.err != nil && time.Since(health.failingSince) > ctrl.UnhealthyAfter {
+		http.Error(w, fmt.Sprintf("can't load the tests since %s: %v", health.failingSince.UTC().Format(time.RFC3339), health.err), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// Readyz is the readiness check, it passes when the last load of the tests succeeded,
+// even if no tests were found
+func (ctrl *Controller) Readyz(w http.ResponseWriter, r *http.Request) {
+
+	ctrl.mu.Lock()
+	health := ctrl.health
+	ctrl.mu.Unlock()
+
+	switch {
+	case !health.attempted:
+		http.Error(w, "tests not loaded yet", http.StatusServiceUnavailable)
+	case health.err != nil:
+		http.Error(w, fmt.Sprintf("can't load the tests: %v", health.err), http.StatusServiceUnavailable)
+	default:
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// Record the outcome of a load of the tests
+func (ctrl *Controller) recordLoad(err error, now time.Time) {
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	if err == nil {
+		ctrl.health.failingSince = time.Time{}
+	} else if ctrl.health.err == nil {
+		ctrl.health.failingSince = now
+	}
+	ctrl.health.err = err
+	ctrl.health.attempted = true
+}
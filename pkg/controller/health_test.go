@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Loader returning the same tests, or error, at every load
+type staticLoader struct {
+	tests []*loader.TestDefinition
+	err   error
+	calls int
+}
+
+func (l *staticLoader) LoadManifests(context.Context, string) ([]*unstructured.Unstructured, error) {
+	return nil, nil
+}
+
+func (l *staticLoader) LoadTests(context.Context, string, map[string]interface{}) ([]*loader.TestDefinition, error) {
+	l.calls++
+	return l.tests, l.err
+}
+
+// Provisioner without TestResults of previous runs
+func newResultsMock() *provisioner.ProvisionerMock {
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("ListWithSelectors", mock.Anything, mock.Anything, mock.Anything).Return(&unstructured.UnstructuredList{}, nil)
+	return prvMock
+}
+
+func getStatusCode(handler http.HandlerFunc, path string) int {
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder.Code
+}
+
+func TestReadyz(t *testing.T) {
+
+	ctrl := NewController(nil, nil, nil, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(ctrl.Readyz, ReadyzPath))
+
+	ctrl.recordLoad(nil, time.Now())
+	assert.Equal(t, http.StatusOK, getStatusCode(ctrl.Readyz, ReadyzPath))
+
+	ctrl.recordLoad(errors.New("connection refused"), time.Now())
+	assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(ctrl.Readyz, ReadyzPath))
+}
+
+func TestHealthz(t *testing.T) {
+
+	ctrl := NewController(nil, nil, nil, nil)
+	ctrl.UnhealthyAfter = time.Minute
+	assert.Equal(t, http.StatusOK, getStatusCode(ctrl.Healthz, HealthzPath))
+
+	// Failing for longer than UnhealthyAfter, the next errors keep the start of the failure
+	ctrl.recordLoad(errors.New("connection refused"), time.Now().Add(-2*time.Minute))
+	ctrl.recordLoad(errors.New("connection refused"), time.Now())
+	assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(ctrl.Healthz, HealthzPath))
+
+	ctrl.recordLoad(nil, time.Now())
+	assert.Equal(t, http.StatusOK, getStatusCode(ctrl.Healthz, HealthzPath))
+
+	ctrl.recordLoad(errors.New("connection refused"), time.Now())
+	assert.Equal(t, http.StatusOK, getStatusCode(ctrl.Healthz, HealthzPath))
+
+	ctrl.UnhealthyAfter = 0
+	ctrl.recordLoad(errors.New("connection refused"), time.Now().Add(-time.Hour))
+	assert.Equal(t, http.StatusOK, getStatusCode(ctrl.Healthz, HealthzPath))
+}
+
+func TestRunOnceWithoutTests(t *testing.T) {
+
+	ldr := &staticLoader{}
+	ctrl := NewController(ldr, newResultsMock(), nil, nil)
+
+	err := ctrl.Run(ctxTest, "tests", map[string]interface{}{}, time.Minute, true)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ldr.calls)
+	assert.Equal(t, http.StatusOK, getStatusCode(ctrl.Readyz, ReadyzPath))
+}
+
+func TestRunOnceLoadError(t *testing.T) {
+
+	ldr := &staticLoader{err: errors.New("connection refused")}
+	ctrl := NewController(ldr, newResultsMock(), nil, nil)
+
+	err := ctrl.Run(ctxTest, "tests", map[string]interface{}{}, time.Minute, true)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(ctrl.Readyz, ReadyzPath))
+}
+
+func TestGetLoadBackoff(t *testing.T) {
+
+	assert.Equal(t, loadRetryBackoff, getLoadBackoff(0, time.Minute))
+	assert.Equal(t, 2*loadRetryBackoff, getLoadBackoff(loadRetryBackoff, time.Minute))
+	assert.Equal(t, time.Minute, getLoadBackoff(40*time.Second, time.Minute))
+	assert.Equal(t, time.Second, getLoadBackoff(0, time.Second))
+}
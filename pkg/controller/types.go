@@ -1,10 +1,19 @@
 package controller
 
 import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
 	"github.com/ish-xyz/go-kubetest/pkg/assert"
+	"github.com/ish-xyz/go-kubetest/pkg/chaos"
+	"github.com/ish-xyz/go-kubetest/pkg/client/clientset/versioned"
+	"github.com/ish-xyz/go-kubetest/pkg/diagnostics"
 	"github.com/ish-xyz/go-kubetest/pkg/loader"
 	"github.com/ish-xyz/go-kubetest/pkg/metrics"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type Controller struct {
@@ -12,4 +21,79 @@ type Controller struct {
 	Provisioner       provisioner.Provisioner
 	MetricsController *metrics.MetricsController
 	Assert            *assert.Assert
+	// Updates the status of the TestDefinitions, nil disables it
+	Client versioned.Interface
+
+	// Lowest severity of a failed assertion that makes a single run (--once) fail
+	FailSeverity string
+	// Runs the chaos actions of the steps, nil disables them
+	Chaos *chaos.Runner
+	// Collects diagnostics when a test fails, nil disables them
+	Diagnostics *diagnostics.Collector
+	// Budget of the diagnostics collection, it doesn't count against the teardown budget
+	DiagnosticsTimeout time.Duration
+
+	// Max number of tests running in parallel
+	Concurrency int
+	// Wait before the first retry of a failed test, doubled after each retry
+	RetryBackoff time.Duration
+	// Budget of the teardown of a test, on top of the test timeout
+	TeardownTimeout time.Duration
+
+	// Default teardown policy, tests can override it
+	TeardownPolicy string
+	// Keep the resources of failed tests, whatever their teardown policy
+	KeepOnFailure bool
+	// How long retained resources are kept, 0 keeps them until the test runs again
+	RetentionTTL time.Duration
+
+	// How long the loader can keep failing before the liveness check fails, 0 disables it
+	UnhealthyAfter time.Duration
+
+	mu       sync.Mutex
+	retained map[string]*retention
+	history  map[string][]runOutcome
+	fixtures map[string]*fixtureState
+	health   loaderHealth
+}
+
+// Spec of the TestResult resource
+type TestResult = v1.TestResultSpec
+
+// State of a running test, undone by teardown
+type testRun struct {
+	created  *createdObjects
+	restores []func(context.Context) error
+}
+
+// Fixture shared by the tests of an execution, users is the number of tests
+// that haven't released it yet. The mutex serializes setup and teardown
+type fixtureState struct {
+	mu      sync.Mutex
+	fixture *loader.Fixture
+	users   int
+	run     *testRun
+	failure string
+}
+
+// Outcome of a run, kept to compute the flakiness of a test
+type runOutcome = v1.RunOutcome
+
+// Outcome of the latest load of the tests, reported by the health checks
+type loaderHealth struct {
+	attempted    bool
+	err          error
+	failingSince time.Time
+}
+
+// Resources of a test kept by the teardown policy
+type retention struct {
+	run     *testRun
+	expires time.Time
+}
+
+// Objects created by a test, in creation order
+type createdObjects struct {
+	keys    map[string]bool
+	objects []*unstructured.Unstructured
 }
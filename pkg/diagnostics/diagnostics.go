@@ -0,0 +1,387 @@
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ish-xyz/go-kubetest/pkg/internal/helpers"
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Return a new diagnostics collector, zero values fall back to the defaults
+func NewCollector(prv provisioner.Provisioner, dir string, maxSummarySize int, tailLines int64) *Collector {
+
+	if maxSummarySize <= 0 {
+		maxSummarySize = defaultMaxSummarySize
+	}
+	if tailLines <= 0 {
+		tailLines = defaultTailLines
+	}
+
+	return &Collector{
+		Provisioner:    prv,
+		Dir:            dir,
+		MaxSummarySize: maxSummarySize,
+		TailLines:      tailLines,
+	}
+}
+
+// Collect the live state of the test objects, the objects matched by the failed assertions,
+// pod logs, events of the collected objects and node conditions.
+// Results are the assertions results of the TestResult, keyed like Assert.Run does
+func (c *Collector) Collect(ctx context.Context, test *loader.TestDefinition, results map[string]bool) *Bundle {
+
+	bundle := &Bundle{Test: test.Name, Files: map[string][]byte{}}
+	objects := newObjectSet()
+	var errors []string
+
+	// Objects of the test, including the ones applied by the steps
+	manifests := append([]*unstructured.Unstructured{}, test.ObjectsList...)
+	for _, step := range test.Steps {
+		manifests = append(manifests, step.ApplyObjects...)
+	}
+	for _, obj := range manifests {
+		live, err := c.Provisioner.Get(ctx, obj)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("can't get %s: %v", helpers.ObjectPath(obj), err))
+			continue
+		}
+		objects.add(live)
+	}
+
+	// Objects matched by the failed assertions
+	for _, assertion := range failedAssertions(test, results) {
+		matched, err := c.assertionObjects(ctx, assertion)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("can't get the objects of assertion %s: %v", assertion.Name, err))
+		}
+		objects.add(matched...)
+	}
+
+	// Pods running in the namespaces created by the test
+	for _, obj := range objects.list() {
+		if obj.GetKind() != "Namespace" {
+			continue
+		}
+		pods, err := c.Provisioner.ListWithSelectors(
+			ctx,
+			map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": obj.GetName()},
+			map[string]interface{}{},
+		)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("can't list pods in %s: %v", obj.GetName(), err))
+			continue
+		}
+		for index := range pods.Items {
+			objects.add(&pods.Items[index])
+		}
+	}
+
+	podLogs := 0
+	for _, obj := range objects.list() {
+		name := fileName(obj)
+
+		data, err := yaml.Marshal(redact(obj).Object)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("can't marshal %s: %v", helpers.ObjectPath(obj), err))
+		} else {
+			bundle.add(filepath.Join("objects", name+".yaml"), data)
+		}
+
+		if events := c.events(ctx, obj); events != nil {
+			bundle.add(filepath.Join("events", name+".txt"), events)
+		}
+
+		if obj.GetKind() == "Pod" && podLogs < maxPodLogs {
+			podLogs++
+			errors = append(errors, c.logs(ctx, obj, bundle)...)
+		}
+	}
+
+	nodes, err := c.nodeConditions(ctx)
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("can't list nodes: %v", err))
+	} else {
+		bundle.add("nodes.txt", nodes)
+	}
+
+	if len(errors) > 0 {
+		bundle.add("errors.txt", []byte(strings.Join(errors, "\n")+"\n"))
+	}
+
+	return bundle
+}
+
+// Return the live objects an assertion has been evaluated on
+func (c *Collector) assertionObjects(ctx context.Context, assertion loader.Assertion) ([]*unstructured.Unstructured, error) {
+
+	var objects []*unstructured.Unstructured
+
+	for _, obj := range assertion.Objects {
+		live, err := c.Provisioner.Get(ctx, obj)
+		if err != nil {
+			continue
+		}
+		objects = append(objects, live)
+	}
+
+	if assertion.Resource == "" || assertion.Type == "expectedAdmission" {
+		return objects, nil
+	}
+
+	gvkData, err := helpers.ParseResource(assertion.Resource)
+	if err != nil {
+		return objects, err
+	}
+	list, err := c.Provisioner.ListWithSelectors(ctx, gvkData, assertion.Selectors)
+	if err != nil {
+		return objects, err
+	}
+	for index := range list.Items {
+		objects = append(objects, &list.Items[index])
+	}
+
+	return objects, nil
+}
+
+// Return the events of an object, describe style, nil if there are none
+func (c *Collector) events(ctx context.Context, obj *unstructured.Unstructured) []byte {
+
+	if obj.GetNamespace() == "" {
+		return nil
+	}
+
+	events, err := c.Provisioner.ListWithSelectors(
+		ctx,
+		map[string]string{"apiVersion": "v1", "kind": "Event", "namespace": obj.GetNamespace()},
+		map[string]interface{}{
+			"involvedObject.kind": obj.GetKind(),
+			"involvedObject.name": obj.GetName(),
+		},
+	)
+	if err != nil || len(events.Items) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tCOUNT\tMESSAGE")
+	for _, event := range events.Items {
+		lastSeen, _, _ := unstructured.NestedString(event.Object, "lastTimestamp")
+		eventType, _, _ := unstructured.NestedString(event.Object, "type")
+		reason, _, _ := unstructured.NestedString(event.Object, "reason")
+		count, _, _ := unstructured.NestedInt64(event.Object, "count")
+		message, _, _ := unstructured.NestedString(event.Object, "message")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", lastSeen, eventType, reason, count, strings.TrimSpace(message))
+	}
+	w.Flush()
+
+	return buf.Bytes()
+}
+
+// Collect the logs of every container of a pod, returning the errors
+func (c *Collector) logs(ctx context.Context, pod *unstructured.Unstructured, bundle *Bundle) []string {
+
+	var errors []string
+
+	containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", "containers")
+	initContainers, _, _ := unstructured.NestedSlice(pod.Object, "spec", "initContainers")
+
+	for _, container := range append(initContainers, containers...) {
+		name, _, _ := unstructured.NestedString(container.(map[string]interface{}), "name")
+		logs, err := c.Provisioner.Logs(ctx, pod.GetNamespace(), pod.GetName(), name, c.TailLines)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("can't get logs of %s/%s[%s]: %v", pod.GetNamespace(), pod.GetName(), name, err))
+			continue
+		}
+		bundle.add(filepath.Join("logs", fmt.Sprintf("%s_%s_%s.log", pod.GetNamespace(), pod.GetName(), name)), []byte(logs))
+	}
+
+	return errors
+}
+
+// Return the conditions of every node
+func (c *Collector) nodeConditions(ctx context.Context) ([]byte, error) {
+
+	nodes, err := c.Provisioner.ListWithSelectors(
+		ctx,
+		map[string]string{"apiVersion": "v1", "kind": "Node", "namespace": ""},
+		map[string]interface{}{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tCONDITION\tSTATUS\tREASON\tMESSAGE")
+	for _, node := range nodes.Items {
+		conditions, _, _ := unstructured.NestedSlice(node.Object, "status", "conditions")
+		for _, condition := range conditions {
+			fields, _ := condition.(map[string]interface{})
+			fmt.Fprintf(w, "%s\t%v\t%v\t%v\t%v\n", node.GetName(), fields["type"], fields["status"], fields["reason"], fields["message"])
+		}
+	}
+	w.Flush()
+
+	return buf.Bytes(), nil
+}
+
+// Add a file to the bundle, only the end of files bigger than the max size is kept
+func (b *Bundle) add(path string, data []byte) {
+
+	if len(data) > maxFileSize {
+		data = append([]byte("(truncated) ...\n"), data[len(data)-maxFileSize:]...)
+	}
+	b.Files[path] = data
+}
+
+// Write the bundle in a new directory named after the test, returning its path
+func (b *Bundle) Write(dir string) (string, error) {
+
+	bundleDir := filepath.Join(dir, fmt.Sprintf("%s-%s", b.Test, time.Now().UTC().Format("20060102-150405")))
+
+	for path, data := range b.Files {
+		target := filepath.Join(bundleDir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return "", err
+		}
+	}
+
+	logrus.Infof("Diagnostics of test '%s' written to %s", b.Test, bundleDir)
+	return bundleDir, nil
+}
+
+// Return a text summary of the bundle no longer than maxSize bytes: the list of files,
+// then errors, node conditions, events and logs until the limit is reached
+func (b *Bundle) Summary(maxSize int) string {
+
+	var paths []string
+	for path := range b.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%d files collected:\n", len(paths))
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "  %s (%d bytes)\n", path, len(b.Files[path]))
+	}
+
+	var sections []string
+	for _, prefix := range []string{"errors.txt", "nodes.txt", "events/", "logs/"} {
+		for _, path := range paths {
+			if strings.HasPrefix(path, prefix) {
+				sections = append(sections, path)
+			}
+		}
+	}
+	for _, path := range sections {
+		fmt.Fprintf(&buf, "\n== %s ==\n%s", path, b.Files[path])
+	}
+
+	summary := buf.String()
+	if len(summary) > maxSize {
+		marker := "\n... (truncated)"
+		if maxSize < len(marker) {
+			return summary[:maxSize]
+		}
+		summary = summary[:maxSize-len(marker)] + marker
+	}
+	return summary
+}
+
+// Return the assertions whose result is false, walking sub-assertions and steps
+// with the same keys used by the TestResult
+func failedAssertions(test *loader.TestDefinition, results map[string]bool) []loader.Assertion {
+
+	var failed []loader.Assertion
+
+	var walk func(prefix string, assertions []loader.Assertion)
+	walk = func(prefix string, assertions []loader.Assertion) {
+		for _, assertion := range assertions {
+			key := prefix + assertion.Name
+			if passed, ok := results[key]; ok && !passed {
+				failed = append(failed, assertion)
+			}
+			walk(key+".", assertion.Assertions)
+		}
+	}
+
+	walk("", test.Assert)
+	for _, step := range test.Steps {
+		walk(step.Name+".", step.Assert)
+	}
+
+	return failed
+}
+
+// Return a copy of the object safe to write in a bundle: the values of Secrets
+// and the last applied configuration (which may hold them too) are removed
+func redact(obj *unstructured.Unstructured) *unstructured.Unstructured {
+
+	obj = obj.DeepCopy()
+
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		if _, ok := annotations[lastAppliedAnnotation]; ok {
+			delete(annotations, lastAppliedAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+	}
+
+	if obj.GroupVersionKind().GroupKind() != secretKind {
+		return obj
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := obj.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range values {
+			values[key] = redactedValue
+		}
+	}
+	return obj
+}
+
+func fileName(obj *unstructured.Unstructured) string {
+
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s_%s", strings.ToLower(obj.GetKind()), obj.GetName())
+	}
+	return fmt.Sprintf("%s_%s_%s", strings.ToLower(obj.GetKind()), obj.GetNamespace(), obj.GetName())
+}
+
+func newObjectSet() *objectSet {
+	return &objectSet{keys: map[string]bool{}}
+}
+
+func (s *objectSet) add(objects ...*unstructured.Unstructured) {
+
+	for _, obj := range objects {
+		key := fmt.Sprintf("%s:%s", obj.GroupVersionKind().GroupKind(), fileName(obj))
+		if s.keys[key] || len(s.objects) >= maxObjects {
+			continue
+		}
+		s.keys[key] = true
+		s.objects = append(s.objects, obj)
+	}
+}
+
+func (s *objectSet) list() []*unstructured.Unstructured {
+	return s.objects
+}
@@ -0,0 +1,208 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var ctxTest = context.TODO()
+
+func TestCollect(t *testing.T) {
+
+	// Prepare test data & mock
+	namespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": "app"},
+	}}
+	pod := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web-1", "namespace": "app"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "web"}},
+		},
+	}}
+	event := unstructured.Unstructured{Object: map[string]interface{}{
+		"type":    "Warning",
+		"reason":  "BackOff",
+		"count":   int64(3),
+		"message": "Back-off restarting failed container",
+	}}
+	node := unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "node-1"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "KubeletNotReady"},
+			},
+		},
+	}}
+	test := &loader.TestDefinition{
+		Name:        "web",
+		ObjectsList: []*unstructured.Unstructured{namespace},
+		Assert: []loader.Assertion{
+			{Name: "web-running", Type: "expectedResources", Resource: "v1:Pod:app", Selectors: map[string]interface{}{"metadata.labels.app": "web"}},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", ctxTest, namespace).Return(namespace, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "app"},
+		map[string]interface{}{"metadata.labels.app": "web"},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{pod}}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "app"},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{pod}}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Event", "namespace": "app"},
+		map[string]interface{}{"involvedObject.kind": "Pod", "involvedObject.name": "web-1"},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{event}}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Node", "namespace": ""},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{node}}, nil)
+	prvMock.On("Logs", ctxTest, "app", "web-1", "web", int64(defaultTailLines)).Return("", errors.New("container not started"))
+
+	// Run tests
+	collector := NewCollector(prvMock, "", 0, 0)
+	bundle := collector.Collect(ctxTest, test, map[string]bool{"web-running": false})
+
+	assert.Contains(t, bundle.Files, "objects/namespace_app.yaml")
+	assert.Contains(t, bundle.Files, "objects/pod_app_web-1.yaml")
+	assert.Contains(t, string(bundle.Files["events/pod_app_web-1.txt"]), "BackOff")
+	assert.Contains(t, string(bundle.Files["nodes.txt"]), "KubeletNotReady")
+	assert.Contains(t, string(bundle.Files["errors.txt"]), "container not started")
+
+	// The pod matched by the assertion and listed in the namespace is collected once
+	prvMock.AssertNumberOfCalls(t, "Logs", 1)
+}
+
+func TestCollectRedactsSecrets(t *testing.T) {
+
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      "db",
+			"namespace": "app",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"stringData":{"password":"hunter2"}}`,
+				"team": "storage",
+			},
+		},
+		"data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+		"stringData": map[string]interface{}{"user": "admin"},
+	}}
+	test := &loader.TestDefinition{
+		Name:        "db",
+		ObjectsList: []*unstructured.Unstructured{secret},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("Get", ctxTest, secret).Return(secret, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Event", "namespace": "app"},
+		map[string]interface{}{"involvedObject.kind": "Secret", "involvedObject.name": "db"},
+	).Return(&unstructured.UnstructuredList{}, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		ctxTest,
+		map[string]string{"apiVersion": "v1", "kind": "Node", "namespace": ""},
+		map[string]interface{}{},
+	).Return(&unstructured.UnstructuredList{}, nil)
+
+	bundle := NewCollector(prvMock, "", 0, 0).Collect(ctxTest, test, map[string]bool{})
+
+	data := string(bundle.Files["objects/secret_app_db.yaml"])
+	assert.Contains(t, data, "password: REDACTED")
+	assert.Contains(t, data, "user: REDACTED")
+	assert.Contains(t, data, "team: storage")
+	assert.NotContains(t, data, "aHVudGVyMg==")
+	assert.NotContains(t, data, "hunter2")
+	assert.NotContains(t, data, "admin")
+
+	// The live object isn't modified
+	assert.Equal(t, "aHVudGVyMg==", secret.Object["data"].(map[string]interface{})["password"])
+}
+
+func TestSummary(t *testing.T) {
+
+	bundle := &Bundle{
+		Test: "web",
+		Files: map[string][]byte{
+			"objects/pod_app_web-1.yaml": []byte("kind: Pod"),
+			"logs/app_web-1_web.log":     []byte(strings.Repeat("panic: boom\n", 100)),
+			"errors.txt":                 []byte("can't list nodes\n"),
+		},
+	}
+
+	summary := bundle.Summary(1 << 20)
+	assert.True(t, strings.HasPrefix(summary, "3 files collected:"))
+	assert.True(t, strings.Index(summary, "== errors.txt ==") < strings.Index(summary, "== logs/app_web-1_web.log =="))
+	assert.NotContains(t, summary, "== objects/")
+
+	summary = bundle.Summary(256)
+	assert.Len(t, summary, 256)
+	assert.True(t, strings.HasSuffix(summary, "... (truncated)"))
+}
+
+func TestWrite(t *testing.T) {
+
+	bundle := &Bundle{
+		Test:  "web",
+		Files: map[string][]byte{"objects/namespace_app.yaml": []byte("kind: Namespace\n")},
+	}
+
+	path, err := bundle.Write(t.TempDir())
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(filepath.Base(path), "web-"))
+
+	data, err := os.ReadFile(filepath.Join(path, "objects", "namespace_app.yaml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "kind: Namespace\n", string(data))
+}
+
+func TestFailedAssertions(t *testing.T) {
+
+	test := &loader.TestDefinition{
+		Assert: []loader.Assertion{
+			{Name: "cni", Type: "anyOf", Assertions: []loader.Assertion{{Name: "calico"}, {Name: "cilium"}}},
+		},
+		Steps: []loader.Step{
+			{Name: "upgrade", Assert: []loader.Assertion{{Name: "rollout"}}},
+		},
+	}
+	results := map[string]bool{
+		"cni":             true,
+		"cni.calico":      false,
+		"cni.cilium":      true,
+		"upgrade.rollout": false,
+	}
+
+	failed := failedAssertions(test, results)
+
+	assert.Len(t, failed, 2)
+	assert.Equal(t, "calico", failed[0].Name)
+	assert.Equal(t, "rollout", failed[1].Name)
+}
@@ -0,0 +1,50 @@
+package diagnostics
+
+import (
+	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Lines of logs collected for each container
+const defaultTailLines = 100
+
+// Max size of the summary attached to a TestResult
+const defaultMaxSummarySize = 16 * 1024
+
+// Limits on the amount of data collected for a single test
+const (
+	maxObjects  = 100
+	maxPodLogs  = 20
+	maxFileSize = 1024 * 1024
+)
+
+// Secret values are replaced in the collected objects, keys are kept
+const redactedValue = "REDACTED"
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+var secretKind = schema.GroupKind{Kind: "Secret"}
+
+// Collector gathers the state of the cluster when a test fails
+type Collector struct {
+	Provisioner provisioner.Provisioner
+	// Bundles are written in this directory, empty attaches a summary to the TestResult instead
+	Dir string
+	// Max size in bytes of the summary attached to the TestResult
+	MaxSummarySize int
+	// Lines of logs collected for each container
+	TailLines int64
+}
+
+// Bundle of files collected for a failed test, paths are relative to the bundle directory
+type Bundle struct {
+	Test  string
+	Files map[string][]byte
+}
+
+// Objects collected in a bundle, an object matched several times is collected once
+type objectSet struct {
+	keys    map[string]bool
+	objects []*unstructured.Unstructured
+}
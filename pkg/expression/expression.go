@@ -0,0 +1,55 @@
+package expression
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"google.golang.org/protobuf/proto"
+)
+
+// Parse and type-check a CEL expression, the expression is evaluated against
+// a single object (object) and the whole list of selected objects (objects)
+func Compile(expr string) (cel.Program, error) {
+
+	env, err := cel.NewEnv(
+		cel.Declarations(
+			decls.NewVar(ObjectVar, decls.NewMapType(decls.String, decls.Dyn)),
+			decls.NewVar(ObjectsVar, decls.NewListType(decls.Dyn)),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	// Fields of unstructured objects are dynamic, the result is checked at evaluation time
+	if !proto.Equal(ast.ResultType(), decls.Bool) && !proto.Equal(ast.ResultType(), decls.Dyn) {
+		return nil, fmt.Errorf("expression must return a bool, got %s", cel.FormatType(ast.ResultType()))
+	}
+
+	return env.Program(ast)
+}
+
+// Evaluate a compiled expression against an object
+func Eval(prg cel.Program, object map[string]interface{}, objects []interface{}) (bool, error) {
+
+	out, _, err := prg.Eval(map[string]interface{}{
+		ObjectVar:  object,
+		ObjectsVar: objects,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %v instead of a bool", out.Value())
+	}
+
+	return result, nil
+}
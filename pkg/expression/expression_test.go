@@ -0,0 +1,70 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileErrors(t *testing.T) {
+
+	_, err := Compile("object.metadata.name ==")
+	assert.NotNil(t, err)
+
+	_, err = Compile("undeclared.spec.replicas > 1")
+	assert.NotNil(t, err)
+
+	// Must return a bool
+	_, err = Compile("size(objects)")
+	assert.NotNil(t, err)
+}
+
+func TestEval(t *testing.T) {
+
+	prg, err := Compile("object.spec.containers.all(c, has(c.resources.limits))")
+	assert.Nil(t, err)
+
+	object := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "nginx",
+					"resources": map[string]interface{}{
+						"limits": map[string]interface{}{"cpu": "1"},
+					},
+				},
+			},
+		},
+	}
+
+	res, err := Eval(prg, object, []interface{}{object})
+	assert.Nil(t, err)
+	assert.True(t, res)
+}
+
+func TestEvalObjects(t *testing.T) {
+
+	prg, err := Compile("objects.exists(o, o.metadata.name == object.metadata.name) && size(objects) == 1")
+	assert.Nil(t, err)
+
+	object := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "pod-1"},
+	}
+
+	res, err := Eval(prg, object, []interface{}{object})
+	assert.Nil(t, err)
+	assert.True(t, res)
+}
+
+func TestEvalNonBool(t *testing.T) {
+
+	prg, err := Compile("object.metadata.name")
+	assert.Nil(t, err)
+
+	object := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "pod-1"},
+	}
+
+	_, err = Eval(prg, object, []interface{}{object})
+	assert.NotNil(t, err)
+}
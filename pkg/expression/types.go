@@ -0,0 +1,7 @@
+package expression
+
+// Variables available to the expressions
+const (
+	ObjectVar  = "object"
+	ObjectsVar = "objects"
+)
@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMaxRetries(t *testing.T) {
+	res := MaxRetries("20s", 2)
+
+	assert.Equal(t, 10, res)
+}
+
+func TestMaxRetriesErrors(t *testing.T) {
+
+	// Will default to 60s
+	limit := MaxRetries("wrongString", 6)
+
+	assert.Equal(t, 10, limit)
+}
+
+func TestSleepCanceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	assert.False(t, Sleep(ctx, 60))
+}
+
+func TestParseResourceClusterWide(t *testing.T) {
+
+	gvkData, err := ParseResource("v1:Namespace")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"apiVersion": "v1", "kind": "Namespace", "namespace": ""}, gvkData)
+}
+
+func TestParseResourceNamespaced(t *testing.T) {
+
+	gvkData, err := ParseResource("v1:Pod:default")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"apiVersion": "v1", "kind": "Pod", "namespace": "default"}, gvkData)
+}
+
+func TestParseResourceErrors(t *testing.T) {
+
+	_, err := ParseResource("v1:Pod:default:name")
+
+	assert.NotNil(t, err)
+}
+
+func TestParseObjectPath(t *testing.T) {
+
+	gvkData, err := ParseObjectPath("v1:Namespace:namespace-1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, gvkData["apiVersion"], "v1")
+	assert.Equal(t, gvkData["kind"], "Namespace")
+	assert.Equal(t, gvkData["namespace"], "")
+	assert.Equal(t, gvkData["name"], "namespace-1")
+
+	gvkData, err = ParseObjectPath("apps/v1:Deployment:default:app")
+
+	assert.Nil(t, err)
+	assert.Equal(t, gvkData["namespace"], "default")
+	assert.Equal(t, gvkData["name"], "app")
+}
+
+func TestParseObjectPathErrors(t *testing.T) {
+
+	_, err := ParseObjectPath("wrong resource path")
+
+	assert.NotNil(t, err)
+
+	_, err = ParseObjectPath("apps/v1:Deployment:default:app:extra")
+
+	assert.NotNil(t, err)
+}
+
+func TestObjectPath(t *testing.T) {
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetName("app")
+	assert.Equal(t, "apps/v1:Deployment:app", ObjectPath(obj))
+
+	obj.SetNamespace("default")
+	assert.Equal(t, "apps/v1:Deployment:default:app", ObjectPath(obj))
+}
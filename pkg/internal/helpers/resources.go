@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ParseResource splits a resource, apiVersion:Kind[:namespace], into the
+// apiVersion, kind and namespace keys of the provisioner selectors
+func ParseResource(resource string) (map[string]string, error) {
+
+	parts := splitPath(resource)
+
+	switch len(parts) {
+	case 2:
+		return map[string]string{"apiVersion": parts[0], "kind": parts[1], "namespace": ""}, nil
+	case 3:
+		return map[string]string{"apiVersion": parts[0], "kind": parts[1], "namespace": parts[2]}, nil
+	}
+	return nil, fmt.Errorf("can't unpack resource path, wrong syntax")
+}
+
+// ParseObjectPath splits the path of an object, apiVersion:Kind[:namespace]:name
+func ParseObjectPath(path string) (map[string]string, error) {
+
+	parts := splitPath(path)
+
+	switch len(parts) {
+	case 3:
+		return map[string]string{
+			"apiVersion": parts[0],
+			"kind":       parts[1],
+			"namespace":  "",
+			"name":       parts[2],
+		}, nil
+	case 4:
+		return map[string]string{
+			"apiVersion": parts[0],
+			"kind":       parts[1],
+			"namespace":  parts[2],
+			"name":       parts[3],
+		}, nil
+	}
+	return map[string]string{}, errors.New("can't retrieve gvk from resourcePath")
+}
+
+// ObjectPath returns the path of an object, apiVersion:Kind[:namespace]:name
+func ObjectPath(obj *unstructured.Unstructured) string {
+
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s:%s:%s", obj.GetAPIVersion(), obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimSuffix(strings.TrimPrefix(path, ":"), ":"), ":")
+}
@@ -0,0 +1,30 @@
+package helpers
+
+import (
+	"context"
+	"time"
+)
+
+// Max wait when the timeout is missing or invalid
+const DefaultTimeout = "60s"
+
+// MaxRetries returns how many times a check runs every interval seconds within waitTime
+func MaxRetries(waitTime string, interval int) int {
+
+	maxWait, err := time.ParseDuration(waitTime)
+	if err != nil {
+		maxWait, _ = time.ParseDuration(DefaultTimeout)
+	}
+	return int(maxWait.Seconds()) / interval
+}
+
+// Sleep waits for the interval in seconds, false if the context is done before
+func Sleep(ctx context.Context, interval int) bool {
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(time.Duration(interval) * time.Second):
+		return true
+	}
+}
@@ -5,7 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"github.com/ish-xyz/go-kubetest/pkg/client"
+	"github.com/ish-xyz/go-kubetest/pkg/expression"
+	"github.com/ish-xyz/go-kubetest/pkg/internal/helpers"
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -20,7 +25,7 @@ func NewKubernetesLoader(prv provisioner.Provisioner) *KubernetesLoader {
 }
 
 // Load testData manifests
-func (ldr *KubernetesLoader) LoadManifests(resourcePath string) ([]*unstructured.Unstructured, error) {
+func (ldr *KubernetesLoader) LoadManifests(ctx context.Context, resourcePath string) ([]*unstructured.Unstructured, error) {
 
 	var objects []*unstructured.Unstructured
 
@@ -28,7 +33,7 @@ func (ldr *KubernetesLoader) LoadManifests(resourcePath string) ([]*unstructured
 	name := strings.Split(resourcePath, ":")[1]
 
 	testResources, err := ldr.Provisioner.ListWithSelectors(
-		context.TODO(),
+		ctx,
 		map[string]string{
 			"apiVersion": "go-kubetest.io/v1",
 			"kind":       "TestResource",
@@ -45,8 +50,13 @@ func (ldr *KubernetesLoader) LoadManifests(resourcePath string) ([]*unstructured
 		return nil, fmt.Errorf("no resource with name %s", name)
 	}
 
-	spec := testResources.Items[0].Object["spec"].(map[string]interface{})
-	manifests := strings.Split(spec["data"].(string), YAMLDelimiter)
+	testResource := &v1.TestResource{}
+	err = client.FromUnstructured(&testResources.Items[0], testResource)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := strings.Split(testResource.Spec.Data, YAMLDelimiter)
 	decUnstructured := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
 
 	for _, manifest := range manifests {
@@ -63,11 +73,11 @@ func (ldr *KubernetesLoader) LoadManifests(resourcePath string) ([]*unstructured
 	return objects, err
 }
 
-// Load TestDefinition resources for a given namespace
-func (ldr *KubernetesLoader) LoadTests(namespace string, selectors map[string]interface{}) ([]*TestDefinition, error) {
+// Load TestDefinition resources for a given namespace, no matching tests isn't an error
+func (ldr *KubernetesLoader) LoadTests(ctx context.Context, namespace string, selectors map[string]interface{}) ([]*TestDefinition, error) {
 	var tests []*TestDefinition
 	testDefinitions, err := ldr.Provisioner.ListWithSelectors(
-		context.TODO(),
+		ctx,
 		map[string]string{
 			"apiVersion": "go-kubetest.io/v1",
 			"kind":       "TestDefinition",
@@ -78,49 +88,426 @@ func (ldr *KubernetesLoader) LoadTests(namespace string, selectors map[string]in
 	if err != nil {
 		return nil, err
 	}
-	if len(testDefinitions.Items) < 1 {
-		return nil, fmt.Errorf("can't retrieve any tests from the Kubernetes API")
-	}
-
 	for _, tdef := range testDefinitions.Items {
 
-		tdef.Object["spec"].(map[string]interface{})["name"] = tdef.GetName()
-		testSpec, err := getTestDefinition(tdef.Object["spec"])
+		testSpec, err := getTestDefinition(&tdef)
 		if err != nil {
-			logrus.Warningf("Can't convert manifest.spec into TestDefinition")
+			logrus.Warningf("Can't convert manifest into TestDefinition: %v", err)
+			tests = append(tests, &TestDefinition{Name: tdef.GetName(), LoadErrors: []string{err.Error()}})
 			continue
 		}
 
-		for _, resource := range testSpec.Resources {
-			objects, err := ldr.LoadManifests(fmt.Sprintf("%s:%s", namespace, resource))
-			if err != nil {
-				logrus.Warningf("Error while loading manifests object in test %s", testSpec.Name)
-				logrus.Debugln(err)
-				continue
-			}
-			testSpec.ObjectsList = append(testSpec.ObjectsList, objects...)
+		err = validateTest(testSpec)
+		if err != nil {
+			logrus.Warningf("Invalid test %s: %v", testSpec.Name, err)
+			testSpec.LoadErrors = append(testSpec.LoadErrors, err.Error())
+			tests = append(tests, testSpec)
+			continue
+		}
+
+		testSpec.ObjectsList = ldr.loadResources(ctx, namespace, testSpec.Name, testSpec.Resources, &testSpec.LoadErrors)
+		ldr.loadAssertionManifests(ctx, namespace, testSpec.Name, testSpec.Assert, &testSpec.LoadErrors)
+
+		for index, step := range testSpec.Steps {
+			testSpec.Steps[index].ApplyObjects = ldr.loadResources(ctx, namespace, testSpec.Name, step.Apply, &testSpec.LoadErrors)
+			testSpec.Steps[index].PatchObjects = ldr.loadResources(ctx, namespace, testSpec.Name, step.Patch, &testSpec.LoadErrors)
+			testSpec.Steps[index].DeleteObjects = ldr.loadResources(ctx, namespace, testSpec.Name, step.Delete, &testSpec.LoadErrors)
+			ldr.loadAssertionManifests(ctx, namespace, testSpec.Name, step.Assert, &testSpec.LoadErrors)
 		}
 
 		tests = append(tests, testSpec)
 
 	}
 
+	tests, err = ldr.resolveFixtures(ctx, namespace, tests)
+	if err != nil {
+		return nil, err
+	}
+
+	return markDependencyCycles(tests), nil
+}
+
+// Validate a test at load time, so that broken tests are never executed
+func validateTest(test *TestDefinition) error {
+
+	if err := validateAssertions(test.Assert); err != nil {
+		return fmt.Errorf("invalid assertions: %v", err)
+	}
+	if err := ValidateTeardownPolicy(test.TeardownPolicy); err != nil {
+		return err
+	}
+	if test.Retries < 0 {
+		return fmt.Errorf("negative retries")
+	}
+	if err := validateTimeout(test.Timeout); err != nil {
+		return err
+	}
+	if err := validateSteps(test.Steps); err != nil {
+		return fmt.Errorf("invalid steps: %v", err)
+	}
+	return nil
+}
+
+// Attach the fixtures to the tests using them, missing fixtures and the load errors
+// of the fixtures are added to the tests. Tests sharing a fixture share the same instance
+func (ldr *KubernetesLoader) resolveFixtures(ctx context.Context, namespace string, tests []*TestDefinition) ([]*TestDefinition, error) {
+
+	needed := false
+	for _, test := range tests {
+		needed = needed || len(test.Fixtures) > 0
+	}
+	if !needed {
+		return tests, nil
+	}
+
+	fixtures, err := ldr.loadFixtures(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, test := range tests {
+		test.FixturesList = nil
+		for _, name := range test.Fixtures {
+			fixture, ok := fixtures[name]
+			if !ok {
+				logrus.Warningf("Invalid test %s: fixture %s not found", test.Name, name)
+				test.LoadErrors = append(test.LoadErrors, fmt.Sprintf("fixture %s not found", name))
+				continue
+			}
+			test.FixturesList = append(test.FixturesList, fixture)
+			for _, loadError := range fixture.LoadErrors {
+				test.LoadErrors = append(test.LoadErrors, fmt.Sprintf("fixture %s: %s", name, loadError))
+			}
+		}
+	}
+
 	return tests, nil
 }
 
-func getTestDefinition(testDef interface{}) (*TestDefinition, error) {
+// Load the TestFixture resources of a namespace, by name
+func (ldr *KubernetesLoader) loadFixtures(ctx context.Context, namespace string) (map[string]*Fixture, error) {
 
-	testDefStruct := &TestDefinition{}
-	uobj := unstructured.Unstructured{Object: testDef.(map[string]interface{})}
-	testDefJson, err := uobj.MarshalJSON()
+	fixtureDefinitions, err := ldr.Provisioner.ListWithSelectors(
+		ctx,
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestFixture",
+			"namespace":  namespace,
+		},
+		map[string]interface{}{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := map[string]*Fixture{}
+	for _, fdef := range fixtureDefinitions.Items {
+
+		fixture, err := getFixture(&fdef)
+		if err != nil {
+			logrus.Warningf("Can't convert manifest into Fixture: %v", err)
+			fixtures[fdef.GetName()] = &Fixture{Name: fdef.GetName(), LoadErrors: []string{err.Error()}}
+			continue
+		}
+
+		fixture.ObjectsList = ldr.loadResources(ctx, namespace, fixture.Name, fixture.Resources, &fixture.LoadErrors)
+		fixtures[fixture.Name] = fixture
+	}
+
+	return fixtures, nil
+}
+
+// Load the objects of a list of TestResources, the missing ones are added to loadErrors
+func (ldr *KubernetesLoader) loadResources(ctx context.Context, namespace, testName string, resources []string, loadErrors *[]string) []*unstructured.Unstructured {
+
+	var objectsList []*unstructured.Unstructured
+
+	for _, resource := range resources {
+		objects, err := ldr.LoadManifests(ctx, fmt.Sprintf("%s:%s", namespace, resource))
+		if err != nil {
+			logrus.Warningf("Error while loading manifests object in test %s", testName)
+			logrus.Debugln(err)
+			*loadErrors = append(*loadErrors, fmt.Sprintf("TestResource %s: %v", resource, err))
+			continue
+		}
+		objectsList = append(objectsList, objects...)
+	}
+
+	return objectsList
+}
+
+// Load the manifests referenced by assertions, including sub-assertions
+func (ldr *KubernetesLoader) loadAssertionManifests(ctx context.Context, namespace, testName string, assertions []Assertion, loadErrors *[]string) {
+
+	for index, assertion := range assertions {
+		ldr.loadAssertionManifests(ctx, namespace, testName, assertion.Assertions, loadErrors)
+
+		if assertion.TestResource == "" {
+			continue
+		}
+		objects, err := ldr.LoadManifests(ctx, fmt.Sprintf("%s:%s", namespace, assertion.TestResource))
+		if err != nil {
+			logrus.Warningf("Error while loading manifests for assertion %s in test %s", assertion.Name, testName)
+			logrus.Debugln(err)
+			*loadErrors = append(*loadErrors, fmt.Sprintf("assertion %s: TestResource %s: %v", assertion.Name, assertion.TestResource, err))
+			continue
+		}
+		assertions[index].Objects = objects
+	}
+}
+
+// Convert a TestDefinition resource into a test, fields of the wrong type are errors
+func getTestDefinition(obj *unstructured.Unstructured) (*TestDefinition, error) {
+
+	definition := &v1.TestDefinition{}
+	err := client.FromUnstructured(obj, definition)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal(testDefJson, testDefStruct)
+	testDefStruct := &TestDefinition{}
+	err = convertSpec(definition.Spec, testDefStruct)
 	if err != nil {
 		return nil, err
 	}
+	testDefStruct.Name = definition.Name
 
 	return testDefStruct, nil
 }
+
+// Convert a TestFixture resource into a fixture, fields of the wrong type are errors
+func getFixture(obj *unstructured.Unstructured) (*Fixture, error) {
+
+	definition := &v1.TestFixture{}
+	err := client.FromUnstructured(obj, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtureStruct := &Fixture{}
+	err = convertSpec(definition.Spec, fixtureStruct)
+	if err != nil {
+		return nil, err
+	}
+	fixtureStruct.Name = definition.Name
+
+	return fixtureStruct, nil
+}
+
+// Copy the spec of a typed resource into the loader model, field names are the same
+func convertSpec(spec interface{}, into interface{}) error {
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, into)
+}
+
+// Validate assertions at load time, so that broken tests are never executed
+func validateAssertions(assertions []Assertion) error {
+
+	for _, assertion := range assertions {
+		if assertion.Severity != "" && SeverityRank(assertion.Severity) < 0 {
+			return fmt.Errorf("assertion %s: unknown severity '%s'", assertion.Name, assertion.Severity)
+		}
+		// A range replaces the comparison, the operator would be ignored
+		if (assertion.Min != nil || assertion.Max != nil) && (assertion.Operator != "" || assertion.Count != 0) {
+			return fmt.Errorf("assertion %s: min and max can't be combined with operator or count", assertion.Name)
+		}
+
+		switch assertion.Type {
+		case "expectedExpression":
+			if _, err := expression.Compile(assertion.Expression); err != nil {
+				return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+			}
+			if assertion.Quantifier != "" && assertion.Quantifier != "all" && assertion.Quantifier != "exists" {
+				return fmt.Errorf("assertion %s: unknown quantifier '%s'", assertion.Name, assertion.Quantifier)
+			}
+		case "allOf", "anyOf":
+			if len(assertion.Assertions) == 0 {
+				return fmt.Errorf("assertion %s: %s requires at least one sub-assertion", assertion.Name, assertion.Type)
+			}
+		case "not":
+			if len(assertion.Assertions) != 1 {
+				return fmt.Errorf("assertion %s: not requires exactly one sub-assertion", assertion.Name)
+			}
+		}
+
+		if _, _, err := provisioner.ParseSelectors(assertion.Selectors); err != nil {
+			return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+		}
+
+		if err := validateAssertions(assertion.Assertions); err != nil {
+			return fmt.Errorf("assertion %s: %v", assertion.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate steps at load time, names are used as prefix in the results
+func validateSteps(steps []Step) error {
+
+	names := map[string]bool{}
+	for index, step := range steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d: missing name", index)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step %s: duplicated name", step.Name)
+		}
+		names[step.Name] = true
+
+		if err := validateAssertions(step.Assert); err != nil {
+			return fmt.Errorf("step %s: %v", step.Name, err)
+		}
+		if err := validateObjectActions(step.Actions); err != nil {
+			return fmt.Errorf("step %s: %v", step.Name, err)
+		}
+		if err := validateChaosActions(step.Chaos); err != nil {
+			return fmt.Errorf("step %s: %v", step.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate the actions on existing objects at load time
+func validateObjectActions(actions []ObjectAction) error {
+
+	for _, action := range actions {
+		if action.Name == "" {
+			return fmt.Errorf("action %s: missing name", action.Type)
+		}
+		if _, err := helpers.ParseObjectPath(action.Resource); err != nil {
+			return fmt.Errorf("action %s: resource '%s': %v", action.Name, action.Resource, err)
+		}
+
+		switch action.Type {
+		case "patch":
+			if action.Patch == "" {
+				return fmt.Errorf("action %s: missing patch", action.Name)
+			}
+			if action.PatchType != "" && action.PatchType != "json" && action.PatchType != "merge" && action.PatchType != "strategic" {
+				return fmt.Errorf("action %s: unknown patch type '%s'", action.Name, action.PatchType)
+			}
+		case "delete":
+		default:
+			return fmt.Errorf("action %s: unknown type '%s'", action.Name, action.Type)
+		}
+	}
+
+	return nil
+}
+
+// Validate chaos actions at load time, the guards are enforced when they run
+func validateChaosActions(actions []ChaosAction) error {
+
+	for _, action := range actions {
+		if action.Name == "" {
+			return fmt.Errorf("chaos action %s: missing name", action.Type)
+		}
+		if action.Count < 0 || action.Replicas < 0 {
+			return fmt.Errorf("chaos action %s: count and replicas can't be negative", action.Name)
+		}
+		if _, _, err := provisioner.ParseSelectors(action.Selectors); err != nil {
+			return fmt.Errorf("chaos action %s: %v", action.Name, err)
+		}
+
+		switch action.Type {
+		case "deletePods", "evictPods":
+			if action.Namespace == "" {
+				return fmt.Errorf("chaos action %s: %s requires a namespace", action.Name, action.Type)
+			}
+		case "scaleDeployment":
+			if action.Namespace == "" || action.Target == "" {
+				return fmt.Errorf("chaos action %s: scaleDeployment requires a namespace and a target", action.Name)
+			}
+		case "cordonNode", "drainNode":
+			if action.Target == "" && len(action.Selectors) == 0 {
+				return fmt.Errorf("chaos action %s: %s requires a target or selectors", action.Name, action.Type)
+			}
+		default:
+			return fmt.Errorf("chaos action %s: unknown type '%s'", action.Name, action.Type)
+		}
+	}
+
+	return nil
+}
+
+// Add a load error to the tests that are part of a dependency cycle, or depend on one,
+// so that the remaining ones can always be run in topological order. Dependencies on
+// tests that haven't been loaded are left to the controller
+func markDependencyCycles(tests []*TestDefinition) []*TestDefinition {
+
+	loaded := map[string]bool{}
+	for _, test := range tests {
+		loaded[test.Name] = true
+	}
+
+	// Kahn's algorithm, tests never reaching zero pending dependencies are in or behind a cycle
+	sorted := map[string]bool{}
+	for progress := true; progress; {
+		progress = false
+		for _, test := range tests {
+			if sorted[test.Name] {
+				continue
+			}
+			ready := true
+			for _, dependency := range test.DependsOn {
+				if loaded[dependency] && !sorted[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				sorted[test.Name] = true
+				progress = true
+			}
+		}
+	}
+
+	for _, test := range tests {
+		if !sorted[test.Name] {
+			logrus.Warningf("Dependency cycle in test %s (dependsOn: %s)", test.Name, strings.Join(test.DependsOn, ", "))
+			test.LoadErrors = append(test.LoadErrors, fmt.Sprintf("dependency cycle (dependsOn: %s)", strings.Join(test.DependsOn, ", ")))
+		}
+	}
+
+	return tests
+}
+
+// Check the test timeout, empty means no timeout
+func validateTimeout(timeout string) error {
+
+	if timeout == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(timeout)
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("invalid timeout '%s'", timeout)
+	}
+	return nil
+}
+
+// Check the teardown policy, empty is valid
+func ValidateTeardownPolicy(policy string) error {
+
+	switch policy {
+	case "", TeardownAlways, TeardownOnSuccess, TeardownNever:
+		return nil
+	}
+	return fmt.Errorf("unknown teardown policy '%s'", policy)
+}
+
+// Return the position of the severity in Severities, -1 if unknown
+func SeverityRank(severity string) int {
+
+	for rank, value := range Severities {
+		if value == severity {
+			return rank
+		}
+	}
+	return -1
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -31,7 +32,7 @@ func TestK8SLoadManifestsErrors(t *testing.T) {
 
 	// Execute
 	ldr := NewKubernetesLoader(prvMock)
-	_, err := ldr.LoadManifests(resourcePath)
+	_, err := ldr.LoadManifests(context.TODO(), resourcePath)
 
 	// Assertions
 	assert.NotNil(t, err)
@@ -63,7 +64,7 @@ func TestK8SLoadManifestsEmptyResult(t *testing.T) {
 
 	// Execute
 	ldr := NewKubernetesLoader(prvMock)
-	_, err := ldr.LoadManifests(resourcePath)
+	_, err := ldr.LoadManifests(context.TODO(), resourcePath)
 
 	// Assertions
 	assert.NotNil(t, err)
@@ -102,7 +103,7 @@ func TestK8SLoadManifests(t *testing.T) {
 
 	// Execute
 	ldr := NewKubernetesLoader(prvMock)
-	res, err := ldr.LoadManifests(resourcePath)
+	res, err := ldr.LoadManifests(context.TODO(), resourcePath)
 
 	// Assertions
 	assert.Nil(t, err)
@@ -111,27 +112,84 @@ func TestK8SLoadManifests(t *testing.T) {
 	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 1)
 }
 
+func TestK8SLoadManifestsInvalidResource(t *testing.T) {
+
+	// Prepare mock and data
+	resourcePath := "namespace:name-of-resource"
+	retObjects := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"data": int64(123),
+					},
+				},
+			},
+		},
+	}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResource",
+			"namespace":  "namespace",
+		},
+		map[string]interface{}{
+			"metadata.name": "name-of-resource",
+		},
+	).Return(retObjects, nil)
+
+	// Execute
+	ldr := NewKubernetesLoader(prvMock)
+	_, err := ldr.LoadManifests(context.TODO(), resourcePath)
+
+	// Assertions
+	assert.NotNil(t, err)
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 1)
+}
+
 func TestGetTestDefinition(t *testing.T) {
 
-	inputData := map[string]interface{}{
-		"name":      "input-data",
-		"resources": []string{},
-		"assert":    []map[string]interface{}{},
-		"teardown":  map[string]interface{}{},
-		"setup":     map[string]interface{}{},
+	inputData := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestDefinition",
+			"metadata": map[string]interface{}{
+				"name": "input-data",
+			},
+			"spec": map[string]interface{}{
+				"resources": []interface{}{},
+				"assert":    []interface{}{},
+				"teardown":  map[string]interface{}{},
+				"setup":     map[string]interface{}{},
+				"retries":   int64(2),
+			},
+		},
 	}
 
 	res, err := getTestDefinition(inputData)
 	assert.Nil(t, err)
 	assert.IsType(t, &TestDefinition{}, res)
 	assert.Equal(t, res.Name, "input-data")
+	assert.Equal(t, res.Retries, 2)
 }
 
 func TestGetTestDefinitionErrors(t *testing.T) {
 
-	wrongData := map[string]interface{}{
-		"name":      123,
-		"resources": "wrong-data",
+	wrongData := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestDefinition",
+			"metadata": map[string]interface{}{
+				"name": "wrong-data",
+			},
+			"spec": map[string]interface{}{
+				"resources": "wrong-data",
+			},
+		},
 	}
 
 	res, err := getTestDefinition(wrongData)
@@ -161,15 +219,33 @@ func TestLoadTestsEmpty(t *testing.T) {
 
 	// Execute
 	ldr := NewKubernetesLoader(prvMock)
-	res, err := ldr.LoadTests(namespace, selectors)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
 
 	// Assertions
-	assert.NotNil(t, err)
-	assert.Nil(t, res)
+	assert.Nil(t, err)
+	assert.Empty(t, res)
 
 	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 1)
 }
 
+func TestLoadTestsContext(t *testing.T) {
+
+	// Prepare mock and data, the requests are canceled with the caller's context
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On("ListWithSelectors", ctx, mock.Anything, mock.Anything).Return(&unstructured.UnstructuredList{}, context.Canceled)
+
+	// Execute
+	ldr := NewKubernetesLoader(prvMock)
+	_, err := ldr.LoadTests(ctx, "default", map[string]interface{}{})
+
+	// Assertions
+	assert.Equal(t, context.Canceled, err)
+	prvMock.AssertCalled(t, "ListWithSelectors", ctx, mock.Anything, mock.Anything)
+}
+
 func TestLoadTestsErrors(t *testing.T) {
 
 	// Prepare mock and data
@@ -192,7 +268,7 @@ func TestLoadTestsErrors(t *testing.T) {
 
 	// Execute
 	ldr := NewKubernetesLoader(prvMock)
-	res, err := ldr.LoadTests(namespace, selectors)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
 
 	// Assertions
 	assert.NotNil(t, err)
@@ -239,7 +315,7 @@ func TestLoadTests(t *testing.T) {
 
 	// Execute
 	ldr := NewKubernetesLoader(prvMock)
-	res, err := ldr.LoadTests(namespace, selectors)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
 
 	// Assertions
 	assert.Nil(t, err)
@@ -249,3 +325,524 @@ func TestLoadTests(t *testing.T) {
 
 	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 1)
 }
+
+func TestLoadTestsFixtures(t *testing.T) {
+
+	newTestDefinition := func(name string, fixtures ...interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "go-kubetest.io/v1",
+				"kind":       "TestDefinition",
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+				"spec": map[string]interface{}{
+					"resources": []interface{}{},
+					"fixtures":  fixtures,
+				},
+			},
+		}
+	}
+	testDefinitions := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			newTestDefinition("webhook", "operator"),
+			newTestDefinition("reconcile", "operator"),
+			newTestDefinition("broken", "missing"),
+		},
+	}
+	fixtures := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"apiVersion": "go-kubetest.io/v1",
+					"kind":       "TestFixture",
+					"metadata": map[string]interface{}{
+						"name": "operator",
+					},
+					"spec": map[string]interface{}{
+						"resources": []interface{}{},
+					},
+				},
+			},
+		},
+	}
+
+	// Prepare mock and data
+	namespace := "default"
+	selectors := map[string]interface{}{}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestDefinition",
+			"namespace":  namespace,
+		},
+		selectors,
+	).Return(testDefinitions, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestFixture",
+			"namespace":  namespace,
+		},
+		map[string]interface{}{},
+	).Return(fixtures, nil)
+
+	// Execute
+	ldr := NewKubernetesLoader(prvMock)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
+
+	// Assertions, tests share the same fixture and missing fixtures are load errors
+	assert.Nil(t, err)
+	assert.Len(t, res, 3)
+	assert.Equal(t, "operator", res[0].FixturesList[0].Name)
+	assert.Same(t, res[0].FixturesList[0], res[1].FixturesList[0])
+	assert.Empty(t, res[0].LoadErrors)
+	assert.Equal(t, []string{"fixture missing not found"}, res[2].LoadErrors)
+
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 2)
+}
+
+func TestLoadTestsAssertionManifests(t *testing.T) {
+
+	testDefinitions := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"apiVersion": "go-kubetest.io/v1",
+					"kind":       "TestDefinition",
+					"metadata": map[string]interface{}{
+						"name": "admission",
+					},
+					"spec": map[string]interface{}{
+						"resources": []interface{}{},
+						"assert": []interface{}{
+							map[string]interface{}{
+								"name":         "deny-privileged",
+								"type":         "expectedAdmission",
+								"testResource": "privileged-pod",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	testResources := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"data": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: privileged\nspec: {}",
+					},
+				},
+			},
+		},
+	}
+
+	// Prepare mock and data
+	namespace := "default"
+	selectors := map[string]interface{}{}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestDefinition",
+			"namespace":  namespace,
+		},
+		selectors,
+	).Return(testDefinitions, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResource",
+			"namespace":  namespace,
+		},
+		map[string]interface{}{
+			"metadata.name": "privileged-pod",
+		},
+	).Return(testResources, nil)
+
+	// Execute
+	ldr := NewKubernetesLoader(prvMock)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Len(t, res[0].Assert[0].Objects, 1)
+	assert.Equal(t, "privileged", res[0].Assert[0].Objects[0].GetName())
+
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 2)
+}
+
+func TestLoadTestsLoadErrors(t *testing.T) {
+
+	testDefinitions := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"apiVersion": "go-kubetest.io/v1",
+					"kind":       "TestDefinition",
+					"metadata": map[string]interface{}{
+						"name": "admission",
+					},
+					"spec": map[string]interface{}{
+						"resources": []interface{}{"missing-manifests"},
+						"assert": []interface{}{
+							map[string]interface{}{
+								"name":         "deny-privileged",
+								"type":         "expectedAdmission",
+								"testResource": "privileged-pod",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Prepare mock and data
+	namespace := "default"
+	selectors := map[string]interface{}{}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestDefinition",
+			"namespace":  namespace,
+		},
+		selectors,
+	).Return(testDefinitions, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResource",
+			"namespace":  namespace,
+		},
+		mock.Anything,
+	).Return(&unstructured.UnstructuredList{}, nil)
+
+	// Execute
+	ldr := NewKubernetesLoader(prvMock)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"TestResource missing-manifests: no resource with name missing-manifests",
+		"assertion deny-privileged: TestResource privileged-pod: no resource with name privileged-pod",
+	}, res[0].LoadErrors)
+
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 3)
+}
+
+func TestLoadTestsInvalidDefinitions(t *testing.T) {
+
+	testDefinitions := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"apiVersion": "go-kubetest.io/v1",
+					"kind":       "TestDefinition",
+					"metadata": map[string]interface{}{
+						"name": "wrong-type",
+					},
+					"spec": map[string]interface{}{
+						"resources": "manifests",
+					},
+				},
+			},
+			{
+				Object: map[string]interface{}{
+					"apiVersion": "go-kubetest.io/v1",
+					"kind":       "TestDefinition",
+					"metadata": map[string]interface{}{
+						"name": "negative-retries",
+					},
+					"spec": map[string]interface{}{
+						"resources": []interface{}{},
+						"retries":   int64(-1),
+					},
+				},
+			},
+		},
+	}
+
+	// Prepare mock and data
+	namespace := "default"
+	selectors := map[string]interface{}{}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestDefinition",
+			"namespace":  namespace,
+		},
+		selectors,
+	).Return(testDefinitions, nil)
+
+	// Execute
+	ldr := NewKubernetesLoader(prvMock)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
+
+	// Assertions, invalid tests are kept with a load error
+	assert.Nil(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, "wrong-type", res[0].Name)
+	assert.Len(t, res[0].LoadErrors, 1)
+	assert.Equal(t, "negative-retries", res[1].Name)
+	assert.Equal(t, []string{"negative retries"}, res[1].LoadErrors)
+
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 1)
+}
+
+func TestValidateAssertions(t *testing.T) {
+
+	assertions := []Assertion{
+		{
+			Name:       "limits",
+			Type:       "expectedExpression",
+			Expression: "object.spec.containers.all(c, has(c.resources.limits))",
+		},
+		{
+			Name: "count",
+			Type: "expectedResources",
+		},
+	}
+	assert.Nil(t, validateAssertions(assertions))
+
+	assertions[0].Quantifier = "some"
+	assert.NotNil(t, validateAssertions(assertions))
+
+	assertions[0].Quantifier = "all"
+	assertions[1].Severity = "major"
+	assert.NotNil(t, validateAssertions(assertions))
+	assertions[1].Severity = SeverityWarning
+	assert.Nil(t, validateAssertions(assertions))
+
+	assertions[0].Quantifier = "exists"
+	assertions[0].Expression = "object.spec.containers.all(c, "
+	assert.NotNil(t, validateAssertions(assertions))
+
+	assertions[0].Expression = "true"
+	assertions[1].Selectors = map[string]interface{}{"labelSelector": "app in (echo"}
+	assert.NotNil(t, validateAssertions(assertions))
+	assertions[1].Selectors = map[string]interface{}{"labelSelector": "app in (echo),!canary"}
+	assert.Nil(t, validateAssertions(assertions))
+
+	max := 10
+	assertions[1].Max = &max
+	assert.Nil(t, validateAssertions(assertions))
+	assertions[1].Operator = "gt"
+	assertions[1].Count = 3
+	assert.NotNil(t, validateAssertions(assertions))
+	assertions[1].Operator = ""
+	assert.NotNil(t, validateAssertions(assertions))
+}
+
+func TestValidateCompositeAssertions(t *testing.T) {
+
+	assertions := []Assertion{
+		{
+			Name: "either-cni",
+			Type: "anyOf",
+			Assertions: []Assertion{
+				{Name: "calico", Type: "expectedResources"},
+				{Name: "cilium", Type: "expectedResources"},
+			},
+		},
+	}
+	assert.Nil(t, validateAssertions(assertions))
+
+	assertions[0].Type = "not"
+	assert.NotNil(t, validateAssertions(assertions))
+
+	// Sub-assertions are validated too
+	assertions[0].Type = "allOf"
+	assertions[0].Assertions[1] = Assertion{Name: "broken", Type: "expectedExpression", Expression: "object."}
+	assert.NotNil(t, validateAssertions(assertions))
+}
+
+func TestLoadTestsStepManifests(t *testing.T) {
+
+	testDefinitions := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"apiVersion": "go-kubetest.io/v1",
+					"kind":       "TestDefinition",
+					"metadata": map[string]interface{}{
+						"name": "upgrade",
+					},
+					"spec": map[string]interface{}{
+						"resources": []interface{}{},
+						"steps": []interface{}{
+							map[string]interface{}{
+								"name":  "apply-v2",
+								"apply": []interface{}{"app-v2"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	testResources := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"data": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec: {}",
+					},
+				},
+			},
+		},
+	}
+
+	// Prepare mock and data
+	namespace := "default"
+	selectors := map[string]interface{}{}
+
+	prvMock := new(provisioner.ProvisionerMock)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestDefinition",
+			"namespace":  namespace,
+		},
+		selectors,
+	).Return(testDefinitions, nil)
+	prvMock.On(
+		"ListWithSelectors",
+		context.TODO(),
+		map[string]string{
+			"apiVersion": "go-kubetest.io/v1",
+			"kind":       "TestResource",
+			"namespace":  namespace,
+		},
+		map[string]interface{}{
+			"metadata.name": "app-v2",
+		},
+	).Return(testResources, nil)
+
+	// Execute
+	ldr := NewKubernetesLoader(prvMock)
+	res, err := ldr.LoadTests(context.TODO(), namespace, selectors)
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Len(t, res[0].Steps, 1)
+	assert.Len(t, res[0].Steps[0].ApplyObjects, 1)
+	assert.Equal(t, "app", res[0].Steps[0].ApplyObjects[0].GetName())
+	assert.Empty(t, res[0].Steps[0].DeleteObjects)
+
+	prvMock.AssertNumberOfCalls(t, "ListWithSelectors", 2)
+}
+
+func TestValidateSteps(t *testing.T) {
+
+	valid := []Step{
+		{Name: "apply-v1"},
+		{Name: "apply-v2", Assert: []Assertion{{Name: "rollout", Type: "expectedResources"}}},
+	}
+	assert.Nil(t, validateSteps(valid))
+
+	assert.NotNil(t, validateSteps([]Step{{Apply: []string{"app"}}}))
+	assert.NotNil(t, validateSteps([]Step{{Name: "apply"}, {Name: "apply"}}))
+	assert.NotNil(t, validateSteps([]Step{
+		{Name: "apply", Assert: []Assertion{{Name: "broken", Type: "not"}}},
+	}))
+}
+
+func TestValidateChaosActions(t *testing.T) {
+
+	valid := []ChaosAction{
+		{Name: "kill-one", Type: "deletePods", Namespace: "app"},
+		{Name: "scale-down", Type: "scaleDeployment", Namespace: "app", Target: "echo"},
+		{Name: "drain", Type: "drainNode", Selectors: map[string]interface{}{"metadata.labels.pool": "workers"}},
+	}
+	assert.Nil(t, validateChaosActions(valid))
+
+	assert.NotNil(t, validateChaosActions([]ChaosAction{{Type: "deletePods", Namespace: "app"}}))
+	assert.NotNil(t, validateChaosActions([]ChaosAction{{Name: "kill", Type: "deletePods"}}))
+	assert.NotNil(t, validateChaosActions([]ChaosAction{{Name: "scale", Type: "scaleDeployment", Namespace: "app"}}))
+	assert.NotNil(t, validateChaosActions([]ChaosAction{{Name: "cordon", Type: "cordonNode"}}))
+	assert.NotNil(t, validateChaosActions([]ChaosAction{{Name: "kill", Type: "deletePods", Namespace: "app", Count: -1}}))
+	assert.NotNil(t, validateChaosActions([]ChaosAction{{Name: "reboot", Type: "rebootNode", Target: "node-1"}}))
+}
+
+func TestValidateObjectActions(t *testing.T) {
+
+	valid := []ObjectAction{
+		{Name: "bump-image", Type: "patch", Resource: "apps/v1:Deployment:app:echo", PatchType: "json", Patch: "[]"},
+		{Name: "flip-flag", Type: "patch", Resource: "v1:ConfigMap:app:flags", Patch: "data: {enabled: 'true'}"},
+		{Name: "remove-class", Type: "delete", Resource: "storage.k8s.io/v1:StorageClass:standard"},
+	}
+	assert.Nil(t, validateObjectActions(valid))
+
+	assert.NotNil(t, validateObjectActions([]ObjectAction{{Type: "delete", Resource: "v1:ConfigMap:app:flags"}}))
+	assert.NotNil(t, validateObjectActions([]ObjectAction{{Name: "flags", Type: "delete", Resource: "v1:ConfigMap"}}))
+	assert.NotNil(t, validateObjectActions([]ObjectAction{{Name: "flags", Type: "delete", Resource: "v1:ConfigMap:app:flags:extra"}}))
+	assert.NotNil(t, validateObjectActions([]ObjectAction{{Name: "flags", Type: "patch", Resource: "v1:ConfigMap:app:flags"}}))
+	assert.NotNil(t, validateObjectActions([]ObjectAction{{Name: "flags", Type: "patch", Resource: "v1:ConfigMap:app:flags", PatchType: "apply", Patch: "{}"}}))
+	assert.NotNil(t, validateObjectActions([]ObjectAction{{Name: "flags", Type: "replace", Resource: "v1:ConfigMap:app:flags"}}))
+}
+
+func TestValidateTeardownPolicy(t *testing.T) {
+
+	for _, policy := range []string{"", TeardownAlways, TeardownOnSuccess, TeardownNever} {
+		assert.Nil(t, ValidateTeardownPolicy(policy))
+	}
+	assert.NotNil(t, ValidateTeardownPolicy("onFailure"))
+}
+
+func TestValidateTimeout(t *testing.T) {
+
+	assert.Nil(t, validateTimeout(""))
+	assert.Nil(t, validateTimeout("10m"))
+	assert.NotNil(t, validateTimeout("10"))
+	assert.NotNil(t, validateTimeout("-1m"))
+}
+
+func TestMarkDependencyCycles(t *testing.T) {
+
+	tests := []*TestDefinition{
+		{Name: "ingress-routing", DependsOn: []string{"ingress-controller"}},
+		{Name: "ingress-controller"},
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "behind-cycle", DependsOn: []string{"a"}},
+		{Name: "self", DependsOn: []string{"self"}},
+		{Name: "not-loaded-dependency", DependsOn: []string{"missing"}},
+	}
+
+	failed := []string{}
+	for _, test := range markDependencyCycles(tests) {
+		if len(test.LoadErrors) > 0 {
+			failed = append(failed, test.Name)
+		}
+	}
+
+	assert.Equal(t, []string{"a", "b", "behind-cycle", "self"}, failed)
+	assert.Equal(t, []string{"dependency cycle (dependsOn: b)"}, tests[2].LoadErrors)
+}
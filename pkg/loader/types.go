@@ -1,16 +1,35 @@
 package loader
 
 import (
+	"context"
+
 	"github.com/ish-xyz/go-kubetest/pkg/provisioner"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 const YAMLDelimiter = "---"
 
+// Assertion severities, only critical failures fail a test
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Teardown policies, resources kept by onSuccess and never are retained for debugging
+const (
+	TeardownAlways    = "always"
+	TeardownOnSuccess = "onSuccess"
+	TeardownNever     = "never"
+)
+
+// Severities ordered from the lowest to the highest
+var Severities = []string{SeverityInfo, SeverityWarning, SeverityCritical}
+
 // Interfaces
 type Loader interface {
-	LoadManifests(string) ([]*unstructured.Unstructured, error)
-	LoadTests(string, map[string]interface{}) ([]*TestDefinition, error)
+	LoadManifests(context.Context, string) ([]*unstructured.Unstructured, error)
+	LoadTests(context.Context, string, map[string]interface{}) ([]*TestDefinition, error)
 }
 
 // Data
@@ -32,7 +51,94 @@ type TestDefinition struct {
 		WaitFor []WaitFor `yaml:"waitFor" json:"waitFor"`
 	} `yaml:"teardown" json:"teardown"`
 
+	// Names of the tests that have to pass before this one runs
+	DependsOn []string `yaml:"dependsOn" json:"dependsOn"`
+
+	// Names of the TestFixtures set up before this test, shared with the other tests
+	Fixtures     []string   `yaml:"fixtures" json:"fixtures"`
+	FixturesList []*Fixture `json:"-"`
+
+	// One of: always, onSuccess, never, empty uses the controller policy
+	TeardownPolicy string `yaml:"teardownPolicy" json:"teardownPolicy"`
+
+	// How many times a failed test runs again, with exponential backoff
+	Retries int `yaml:"retries" json:"retries"`
+
+	// Max duration of setup, assertions and steps, teardown has its own budget
+	Timeout string `yaml:"timeout" json:"timeout"`
+
 	Assert []Assertion `yaml:"assert" json:"assert"`
+
+	// Ordered phases, run one after the other once the assertions above passed
+	Steps []Step `yaml:"steps" json:"steps"`
+
+	// Errors found while loading the test (e.g. missing TestResources),
+	// a test with load errors fails without running
+	LoadErrors []string `yaml:"-" json:"-"`
+}
+
+// Resources shared by several tests (e.g. an operator), set up once before the
+// first test using them and torn down after the last one of an execution
+type Fixture struct {
+	Name        string   `yaml:"name" json:"name"`
+	Resources   []string `yaml:"resources" json:"resources"`
+	ObjectsList []*unstructured.Unstructured
+
+	Setup struct {
+		WaitFor []WaitFor `yaml:"waitFor" json:"waitFor"`
+	} `yaml:"setup" json:"setup"`
+
+	Teardown struct {
+		WaitFor []WaitFor `yaml:"waitFor" json:"waitFor"`
+	} `yaml:"teardown" json:"teardown"`
+
+	LoadErrors []string `yaml:"-" json:"-"`
+}
+
+// A phase of a multi-step test: TestResources are applied, patched and deleted
+// in this order, then the step waits for its resources and runs its assertions
+type Step struct {
+	Name            string      `yaml:"name" json:"name"`
+	Apply           []string    `yaml:"apply" json:"apply"`
+	Patch           []string    `yaml:"patch" json:"patch"`
+	Delete          []string    `yaml:"delete" json:"delete"`
+	WaitFor         []WaitFor   `yaml:"waitFor" json:"waitFor"`
+	WaitForDeletion []WaitFor   `yaml:"waitForDeletion" json:"waitForDeletion"`
+	Assert          []Assertion `yaml:"assert" json:"assert"`
+
+	// Actions on existing objects, run after the TestResources, reverted by teardown
+	Actions []ObjectAction `yaml:"actions" json:"actions"`
+
+	// Disruptive actions run after the objects have been deleted, before the waits
+	Chaos []ChaosAction `yaml:"chaos" json:"chaos"`
+
+	ApplyObjects  []*unstructured.Unstructured `yaml:"-" json:"-"`
+	PatchObjects  []*unstructured.Unstructured `yaml:"-" json:"-"`
+	DeleteObjects []*unstructured.Unstructured `yaml:"-" json:"-"`
+}
+
+// Patch or delete an existing object, resource is apiVersion:Kind[:namespace]:name.
+// PatchType is one of: json, merge (default), strategic; patch is a YAML or JSON document
+type ObjectAction struct {
+	Name      string `yaml:"name" json:"name"`
+	Type      string `yaml:"type" json:"type"`
+	Resource  string `yaml:"resource" json:"resource"`
+	PatchType string `yaml:"patchType" json:"patchType"`
+	Patch     string `yaml:"patch" json:"patch"`
+}
+
+// A disruptive action, one of: deletePods, evictPods, cordonNode, drainNode, scaleDeployment.
+// Pods are picked randomly among the ones matching the selectors, nodes and deployments
+// are picked by name (target) or randomly among the ones matching the selectors
+type ChaosAction struct {
+	Name      string                 `yaml:"name" json:"name"`
+	Type      string                 `yaml:"type" json:"type"`
+	Namespace string                 `yaml:"namespace" json:"namespace"`
+	Target    string                 `yaml:"target" json:"target"`
+	Selectors map[string]interface{} `yaml:"selectors" json:"selectors"`
+	Count     int                    `yaml:"count" json:"count"`
+	Replicas  int                    `yaml:"replicas" json:"replicas"`
+	Timeout   string                 `yaml:"timeout" json:"timeout"`
 }
 
 type WaitFor struct {
@@ -49,6 +155,57 @@ type Assertion struct {
 	Selectors map[string]interface{} `yaml:"selectors" json:"selectors"`
 	Count     int                    `yaml:"count" json:"count"`
 	Errors    []string               `yaml:"errors" json:"errors"`
+	Severity  string                 `yaml:"severity" json:"severity"`
+
+	// Count comparisons for expectedResources, min/max take precedence over operator
+	Min      *int   `yaml:"min" json:"min"`
+	Max      *int   `yaml:"max" json:"max"`
+	Operator string `yaml:"operator" json:"operator"`
+	Absent   bool   `yaml:"absent" json:"absent"`
+	Stable   string `yaml:"stable" json:"stable"`
+
+	// Fields (dotted paths) excluded from or re-included into the expectedState comparison
+	IgnoreFields  []string `yaml:"ignoreFields" json:"ignoreFields"`
+	IncludeFields []string `yaml:"includeFields" json:"includeFields"`
+
+	// Free-form parameters passed to plugin assertions
+	Params map[string]interface{} `yaml:"params" json:"params"`
+
+	// Sub-assertions of the composite types: allOf, anyOf, not
+	Assertions []Assertion `yaml:"assertions" json:"assertions"`
+
+	// CEL expression for expectedExpression, quantifier is one of: all, exists,
+	// all fails when no object is selected unless allowEmpty is set
+	Expression string `yaml:"expression" json:"expression"`
+	Quantifier string `yaml:"quantifier" json:"quantifier"`
+	AllowEmpty bool   `yaml:"allowEmpty" json:"allowEmpty"`
+
+	// Structured matchers for expectedErrors, matched in any order
+	MatchErrors      []ErrorMatcher `yaml:"matchErrors" json:"matchErrors"`
+	AllowExtraErrors bool           `yaml:"allowExtraErrors" json:"allowExtraErrors"`
+
+	// TestResource holding the manifests used by expectedAdmission and expectedState
+	TestResource string                       `yaml:"testResource" json:"testResource"`
+	Admission    AdmissionExpectation         `yaml:"admission" json:"admission"`
+	Objects      []*unstructured.Unstructured `yaml:"-" json:"-"`
+}
+
+// Empty fields match anything, reason and message are regular expressions
+type ErrorMatcher struct {
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string `yaml:"kind" json:"kind"`
+	Namespace  string `yaml:"namespace" json:"namespace"`
+	Name       string `yaml:"name" json:"name"`
+	Reason     string `yaml:"reason" json:"reason"`
+	Code       int    `yaml:"code" json:"code"`
+	Message    string `yaml:"message" json:"message"`
+}
+
+type AdmissionExpectation struct {
+	Allowed bool   `yaml:"allowed" json:"allowed"`
+	Reason  string `yaml:"reason" json:"reason"`
+	Message string `yaml:"message" json:"message"`
+	Code    int    `yaml:"code" json:"code"`
 }
 
 // Loaders
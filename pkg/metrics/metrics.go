@@ -4,28 +4,21 @@ import (
 	"fmt"
 	"net/http"
 
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"github.com/ish-xyz/go-kubetest/pkg/client"
+	"github.com/ish-xyz/go-kubetest/pkg/loader"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
-	"k8s.io/client-go/tools/cache"
 )
 
-var resource = schema.GroupVersionResource{
-	Group:    "go-kubetest.io",
-	Version:  "v1",
-	Resource: "testresults",
-}
-
 func NewMetricsController(dc dynamic.Interface, address string, port int) *MetricsController {
 	return &MetricsController{
-		DynClient: dc,
-		Port:      port,
-		Address:   address,
-		Path:      "/metrics",
+		Client:  dc,
+		Port:    port,
+		Address: address,
+		Path:    "/metrics",
 		Metrics: Metrics{
 			TestStatus: promauto.NewGaugeVec(
 				prometheus.GaugeOpts{
@@ -44,6 +37,25 @@ func NewMetricsController(dc dynamic.Interface, address string, port int) *Metri
 				[]string{
 					"name",
 					"assertion",
+					"severity",
+				},
+			),
+			TestFlakiness: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: "kubetest_test_flakiness",
+					Help: "Share of the recent runs of a given test that passed on retry or flipped result, between 0 and 1",
+				},
+				[]string{
+					"name",
+				},
+			),
+			TestLoadErrors: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: "kubetest_test_load_errors",
+					Help: "Number of errors found while loading a given test, a test with load errors fails without running",
+				},
+				[]string{
+					"name",
 				},
 			),
 			TotalTests: promauto.NewGauge(
@@ -75,60 +87,60 @@ func (m *MetricsController) Run(namespace string) {
 	go http.ListenAndServe(fmt.Sprintf("%s:%d", m.Address, m.Port), nil)
 
 	// Init informer and run it
-	sharedInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(m.DynClient, 0, namespace, nil)
-	genericInformer := sharedInformerFactory.ForResource(resource)
+	informer := client.NewTestResultInformer(m.Client, namespace, 0)
 	stopCh := make(chan struct{})
 	defer close(stopCh)
 
-	sharedInformer := genericInformer.Informer()
-	handlers := cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			m.AddMetrics(obj.(*unstructured.Unstructured))
-		},
-		UpdateFunc: func(oldObj, obj interface{}) {
-			m.UpdateMetrics(obj.(*unstructured.Unstructured))
-		},
-		DeleteFunc: func(obj interface{}) {
-			m.DeleteMetrics(obj.(*unstructured.Unstructured))
-		},
-	}
-	sharedInformer.AddEventHandler(handlers)
-	go sharedInformer.Run(stopCh)
+	informer.AddEventHandler(client.TestResultHandlerFuncs{
+		AddFunc:    m.AddMetrics,
+		UpdateFunc: m.UpdateMetrics,
+		DeleteFunc: m.DeleteMetrics,
+	})
+	go informer.Run(stopCh)
 
 	// wait forever
 	select {}
 }
 
-func (m *MetricsController) AddMetrics(obj *unstructured.Unstructured) {
+func (m *MetricsController) AddMetrics(obj *v1.TestResult) {
 
 	delete := false
-	spec := obj.Object["spec"].(map[string]interface{})
+	spec := obj.Spec
 
-	m.setMetricTestStatus(delete, obj.GetName(), spec["result"].(bool))
-	m.setMetricAssertionStatus(delete, obj.GetName(), spec["assertions"].(map[string]interface{}))
+	m.setMetricTestStatus(delete, obj.Name, spec.Result)
+	m.setMetricAssertionStatus(delete, obj.Name, spec.Assertions, spec.Severities)
+	m.setMetricTestFlakiness(delete, obj.Name, spec.Flakiness)
+	m.setMetricTestLoadErrors(delete, obj.Name, spec.LoadErrors)
 	m.setMetricTotalTests(delete)
-	m.setMetricTotalTestsPassed(delete, spec["result"].(bool))
-	m.setMetricTotalTestsFailed(delete, spec["result"].(bool))
+	m.setMetricTotalTestsPassed(delete, spec.Result)
+	m.setMetricTotalTestsFailed(delete, spec.Result)
 }
 
-func (m *MetricsController) UpdateMetrics(obj *unstructured.Unstructured) {
+func (m *MetricsController) UpdateMetrics(oldObj, obj *v1.TestResult) {
 	delete := false
-	spec := obj.Object["spec"].(map[string]interface{})
+	spec := obj.Spec
+
+	// Drop the assertion series of the previous run, severities may have changed
+	m.setMetricAssertionStatus(true, oldObj.Name, oldObj.Spec.Assertions, oldObj.Spec.Severities)
 
-	m.setMetricTestStatus(delete, obj.GetName(), spec["result"].(bool))
-	m.setMetricAssertionStatus(delete, obj.GetName(), spec["assertions"].(map[string]interface{}))
+	m.setMetricTestStatus(delete, obj.Name, spec.Result)
+	m.setMetricAssertionStatus(delete, obj.Name, spec.Assertions, spec.Severities)
+	m.setMetricTestFlakiness(delete, obj.Name, spec.Flakiness)
+	m.setMetricTestLoadErrors(delete, obj.Name, spec.LoadErrors)
 }
 
-func (m *MetricsController) DeleteMetrics(obj *unstructured.Unstructured) {
+func (m *MetricsController) DeleteMetrics(obj *v1.TestResult) {
 
 	delete := true
-	spec := obj.Object["spec"].(map[string]interface{})
+	spec := obj.Spec
 
-	m.setMetricTestStatus(delete, obj.GetName(), spec["result"].(bool))
-	m.setMetricAssertionStatus(delete, obj.GetName(), spec["assertions"].(map[string]interface{}))
+	m.setMetricTestStatus(delete, obj.Name, spec.Result)
+	m.setMetricAssertionStatus(delete, obj.Name, spec.Assertions, spec.Severities)
+	m.setMetricTestFlakiness(delete, obj.Name, spec.Flakiness)
+	m.setMetricTestLoadErrors(delete, obj.Name, spec.LoadErrors)
 	m.setMetricTotalTests(delete)
-	m.setMetricTotalTestsPassed(delete, spec["result"].(bool))
-	m.setMetricTotalTestsFailed(delete, spec["result"].(bool))
+	m.setMetricTotalTestsPassed(delete, spec.Result)
+	m.setMetricTotalTestsFailed(delete, spec.Result)
 
 }
 
@@ -140,18 +152,34 @@ func (m *MetricsController) setMetricTestStatus(delete bool, key string, value b
 	m.Metrics.TestStatus.WithLabelValues(key).Set(getPromVal(value))
 }
 
-func (m *MetricsController) setMetricAssertionStatus(delete bool, testName string, assertions map[string]interface{}) {
+func (m *MetricsController) setMetricAssertionStatus(delete bool, testName string, assertions map[string]bool, severities map[string]string) {
 
 	if delete {
 		for key, _ := range assertions {
-			m.Metrics.AssertionStatus.DeleteLabelValues(testName, key)
+			m.Metrics.AssertionStatus.DeleteLabelValues(testName, key, getSeverity(severities, key))
 		}
 		return
 	}
 
 	for key, value := range assertions {
-		m.Metrics.AssertionStatus.WithLabelValues(testName, key).Set(getPromVal(value.(bool)))
+		m.Metrics.AssertionStatus.WithLabelValues(testName, key, getSeverity(severities, key)).Set(getPromVal(value))
+	}
+}
+
+func (m *MetricsController) setMetricTestFlakiness(delete bool, key string, value float64) {
+	if delete {
+		m.Metrics.TestFlakiness.DeleteLabelValues(key)
+		return
+	}
+	m.Metrics.TestFlakiness.WithLabelValues(key).Set(value)
+}
+
+func (m *MetricsController) setMetricTestLoadErrors(delete bool, key string, loadErrors []string) {
+	if delete {
+		m.Metrics.TestLoadErrors.DeleteLabelValues(key)
+		return
 	}
+	m.Metrics.TestLoadErrors.WithLabelValues(key).Set(float64(len(loadErrors)))
 }
 
 func (m *MetricsController) setMetricTotalTests(delete bool) {
@@ -178,6 +206,14 @@ func (m *MetricsController) setMetricTotalTestsFailed(delete bool, result bool)
 	m.Metrics.TotalTestsFailed.Add(getPromVal(!result))
 }
 
+func getSeverity(severities map[string]string, key string) string {
+
+	if severity, ok := severities[key]; ok {
+		return severity
+	}
+	return loader.SeverityCritical
+}
+
 func getPromVal(result bool) float64 {
 	if result {
 		return 1
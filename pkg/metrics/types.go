@@ -11,12 +11,14 @@ type Metrics struct {
 	TotalTestsPassed prometheus.Gauge
 	TotalTestsFailed prometheus.Gauge
 	AssertionStatus  *prometheus.GaugeVec
+	TestFlakiness    *prometheus.GaugeVec
+	TestLoadErrors   *prometheus.GaugeVec
 }
 
 type MetricsController struct {
-	DynClient dynamic.Interface
-	Port      int
-	Address   string
-	Path      string
-	Metrics   Metrics
+	Client  dynamic.Interface
+	Port    int
+	Address string
+	Path    string
+	Metrics Metrics
 }
@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Return a new plugin manager with the executables discovered in dir
+func NewManager(dir, kubeconfig, kubeContext string) (*Manager, error) {
+
+	plugins, err := Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		Plugins:    plugins,
+		Kubeconfig: kubeconfig,
+		Context:    kubeContext,
+	}, nil
+}
+
+// Discover the executables named kubetest-<type> in a directory
+func Discover(dir string) (map[string]string, error) {
+
+	plugins := map[string]string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), Prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			logrus.Debugf("Skipping plugin %s, not executable", entry.Name())
+			continue
+		}
+
+		assertionType := strings.TrimPrefix(entry.Name(), Prefix)
+		plugins[assertionType] = filepath.Join(dir, entry.Name())
+		logrus.Debugf("Discovered plugin for assertion type '%s'", assertionType)
+	}
+
+	return plugins, nil
+}
+
+// Check if a plugin is registered for the assertion type
+func (m *Manager) Has(assertionType string) bool {
+
+	if m == nil {
+		return false
+	}
+	_, ok := m.Plugins[assertionType]
+	return ok
+}
+
+// Execute the plugin registered for the assertion type, the stderr of
+// the plugin is returned in any case so it can be attached to the result
+func (m *Manager) Run(ctx context.Context, assertionType string, assertion interface{}, timeout string) (*Output, string, error) {
+
+	path, ok := m.Plugins[assertionType]
+	if !ok {
+		return nil, "", fmt.Errorf("no plugin for assertion type '%s'", assertionType)
+	}
+
+	maxWait, err := time.ParseDuration(timeout)
+	if err != nil {
+		maxWait, _ = time.ParseDuration(defaultTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	input, err := json.Marshal(Input{
+		Assertion:  assertion,
+		Kubeconfig: m.Kubeconfig,
+		Context:    m.Context,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	errOutput := truncate(strings.TrimSpace(stderr.String()), maxStderr)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errOutput, fmt.Errorf("plugin %s timed out after %s", filepath.Base(path), maxWait)
+	}
+	if err != nil {
+		return nil, errOutput, fmt.Errorf("plugin %s failed: %v", filepath.Base(path), err)
+	}
+
+	output := &Output{}
+	err = json.Unmarshal(stdout.Bytes(), output)
+	if err != nil {
+		return nil, errOutput, fmt.Errorf("plugin %s returned an invalid result: %v", filepath.Base(path), err)
+	}
+
+	return output, errOutput, nil
+}
+
+// Keep the last max bytes, the end of stderr is usually the most relevant
+func truncate(data string, max int) string {
+
+	if len(data) <= max {
+		return data
+	}
+	return "..." + data[len(data)-max:]
+}
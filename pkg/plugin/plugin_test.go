@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) {
+
+	err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode)
+	assert.Nil(t, err)
+}
+
+func TestDiscover(t *testing.T) {
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "kubetest-loadBalancer", "#!/bin/sh\n", 0755)
+	writePlugin(t, dir, "kubetest-notExecutable", "#!/bin/sh\n", 0644)
+	writePlugin(t, dir, "other-tool", "#!/bin/sh\n", 0755)
+
+	plugins, err := Discover(dir)
+
+	assert.Nil(t, err)
+	assert.Len(t, plugins, 1)
+	assert.Equal(t, filepath.Join(dir, "kubetest-loadBalancer"), plugins["loadBalancer"])
+}
+
+func TestDiscoverErrors(t *testing.T) {
+
+	_, err := Discover(filepath.Join(t.TempDir(), "missing"))
+
+	assert.NotNil(t, err)
+}
+
+func TestRun(t *testing.T) {
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "kubetest-echo", `#!/bin/sh
+input=$(cat)
+echo "checking load balancer" >&2
+case "$input" in
+  *'"context":"kind-test"'*) echo '{"passed": true, "message": "found"}' ;;
+  *) echo '{"passed": false, "message": "wrong input"}' ;;
+esac
+`, 0755)
+
+	mgr, err := NewManager(dir, "/tmp/kubeconfig", "kind-test")
+	assert.Nil(t, err)
+	assert.True(t, mgr.Has("echo"))
+
+	output, stderr, err := mgr.Run(context.TODO(), "echo", map[string]interface{}{"name": "lb"}, "5s")
+
+	assert.Nil(t, err)
+	assert.True(t, output.Passed)
+	assert.Equal(t, "found", output.Message)
+	assert.Equal(t, "checking load balancer", stderr)
+}
+
+func TestRunTimeout(t *testing.T) {
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "kubetest-slow", "#!/bin/sh\necho 'starting' >&2\nexec sleep 10\n", 0755)
+
+	mgr, err := NewManager(dir, "", "")
+	assert.Nil(t, err)
+
+	_, stderr, err := mgr.Run(context.TODO(), "slow", nil, "1s")
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Equal(t, "starting", stderr)
+}
+
+func TestRunInvalidOutput(t *testing.T) {
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "kubetest-broken", "#!/bin/sh\necho 'not json'\n", 0755)
+
+	mgr, err := NewManager(dir, "", "")
+	assert.Nil(t, err)
+
+	_, _, err = mgr.Run(context.TODO(), "broken", nil, "5s")
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid result")
+}
+
+func TestHasNilManager(t *testing.T) {
+
+	var mgr *Manager
+
+	assert.False(t, mgr.Has("anything"))
+}
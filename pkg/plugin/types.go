@@ -0,0 +1,29 @@
+package plugin
+
+// Executables in the plugin directory named kubetest-<type> handle assertions of <type>
+const Prefix = "kubetest-"
+
+const defaultTimeout = "60s"
+
+// Max number of stderr bytes kept in the result
+const maxStderr = 4096
+
+// Input written as JSON to the plugin stdin
+type Input struct {
+	Assertion  interface{} `json:"assertion"`
+	Kubeconfig string      `json:"kubeconfig"`
+	Context    string      `json:"context"`
+}
+
+// Output read as JSON from the plugin stdout
+type Output struct {
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+type Manager struct {
+	// Assertion type -> executable path
+	Plugins    map[string]string
+	Kubeconfig string
+	Context    string
+}
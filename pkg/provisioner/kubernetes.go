@@ -3,16 +3,22 @@ package provisioner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -21,6 +27,9 @@ import (
 
 const defaultNamespace = "default"
 
+// Max duration of a list request, so that a hung API call doesn't block the caller
+const listTimeout = 30 * time.Second
+
 // Return a provisioner instance used to create, update & delete
 // 		cluster-wide or namespaced resources on Kubernetes cluster
 func NewProvisioner(cfg *rest.Config, client *kubernetes.Clientset, dynClient dynamic.Interface) *Kubernetes {
@@ -34,39 +43,27 @@ func NewProvisioner(cfg *rest.Config, client *kubernetes.Clientset, dynClient dy
 
 // Create or update an unstructured resource
 func (k *Kubernetes) CreateOrUpdate(ctx context.Context, obj *unstructured.Unstructured) error {
+	return k.apply(ctx, obj, false)
+}
 
-	var dr dynamic.ResourceInterface
+// Submit an unstructured resource with server-side dryRun=All (admission only, nothing is persisted)
+func (k *Kubernetes) DryRun(ctx context.Context, obj *unstructured.Unstructured) error {
+	return k.apply(ctx, obj, true)
+}
 
-	// Init discovery client and mapper
-	dc, err := discovery.NewDiscoveryClientForConfig(k.Config)
-	if err != nil {
-		logrus.Debugln(err)
-		return err
-	}
+// Server-side apply an unstructured resource
+func (k *Kubernetes) apply(ctx context.Context, obj *unstructured.Unstructured, dryRun bool) error {
 
-	// Get GVR
-	groupResources, err := restmapper.GetAPIGroupResources(dc)
+	dr, err := k.resourceClient(obj.GroupVersionKind().GroupKind(), obj.GetNamespace())
 	if err != nil {
-		logrus.Debugln(err)
 		return err
 	}
 
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
-	mapping, err := mapper.RESTMapping(schema.ParseGroupKind(obj.GroupVersionKind().GroupKind().String()))
-	if err != nil {
-		logrus.Debugln(err)
-		return err
+	patchOptions := metav1.PatchOptions{
+		FieldManager: "go-kubetest",
 	}
-
-	// Default to "default" namespace if not specified
-	namespace := obj.GetNamespace()
-	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace == "" {
-		namespace = defaultNamespace
-	}
-
-	dr = k.DynClient.Resource(mapping.Resource)
-	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
-		dr = k.DynClient.Resource(mapping.Resource).Namespace(namespace)
+	if dryRun {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
 	}
 
 	// Check if namespace is empty and if resource is namespaced or not
@@ -76,9 +73,7 @@ func (k *Kubernetes) CreateOrUpdate(ctx context.Context, obj *unstructured.Unstr
 		obj.GetName(),
 		types.ApplyPatchType,
 		data,
-		metav1.PatchOptions{
-			FieldManager: "go-kubetest",
-		},
+		patchOptions,
 	)
 
 	return err
@@ -87,129 +82,219 @@ func (k *Kubernetes) CreateOrUpdate(ctx context.Context, obj *unstructured.Unstr
 // Delete an unstructured resource
 func (k *Kubernetes) Delete(ctx context.Context, obj *unstructured.Unstructured) error {
 
-	var dr dynamic.ResourceInterface
-
-	// Init discovery client and mapper
-	dc, err := discovery.NewDiscoveryClientForConfig(k.Config)
+	dr, err := k.resourceClient(obj.GroupVersionKind().GroupKind(), obj.GetNamespace())
 	if err != nil {
-		logrus.Debugln(err)
 		return err
 	}
 
-	// Get GVR
-	groupResources, err := restmapper.GetAPIGroupResources(dc)
-	if err != nil {
-		logrus.Debugln(err)
-		return err
+	// Exec rest request to API
+	deletePolicy := metav1.DeletePropagationForeground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &deletePolicy,
 	}
+	err = dr.Delete(ctx, obj.GetName(), deleteOptions)
+
+	return err
+}
 
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
-	mapping, err := mapper.RESTMapping(schema.ParseGroupKind(obj.GroupVersionKind().GroupKind().String()))
+// Patch an existing resource, obj only needs to identify the resource (GVK, namespace and name)
+func (k *Kubernetes) Patch(ctx context.Context, obj *unstructured.Unstructured, patchType types.PatchType, data []byte) error {
+
+	dr, err := k.resourceClient(obj.GroupVersionKind().GroupKind(), obj.GetNamespace())
 	if err != nil {
-		logrus.Debugln(err)
 		return err
 	}
 
-	// Default to "default" namespace if not specified
-	namespace := obj.GetNamespace()
-	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace == "" {
+	_, err = dr.Patch(ctx, obj.GetName(), patchType, data, metav1.PatchOptions{
+		FieldManager: "go-kubetest",
+	})
+	return err
+}
+
+// Evict a pod through the Eviction API, so that PodDisruptionBudgets are respected
+func (k *Kubernetes) Evict(ctx context.Context, pod *unstructured.Unstructured) error {
+
+	namespace := pod.GetNamespace()
+	if namespace == "" {
 		namespace = defaultNamespace
 	}
 
-	dr = k.DynClient.Resource(mapping.Resource)
-	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
-		dr = k.DynClient.Resource(mapping.Resource).Namespace(namespace)
-	}
+	return k.Client.CoreV1().Pods(namespace).EvictV1(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.GetName(),
+			Namespace: namespace,
+		},
+	})
+}
 
-	// Exec rest request to API
-	deletePolicy := metav1.DeletePropagationForeground
-	deleteOptions := metav1.DeleteOptions{
-		PropagationPolicy: &deletePolicy,
+// Return the last lines of the logs of a pod container
+func (k *Kubernetes) Logs(ctx context.Context, namespace, name, container string, tailLines int64) (string, error) {
+
+	data, err := k.Client.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+	}).DoRaw(ctx)
+
+	return string(data), err
+}
+
+// Get the live state of an unstructured resource
+func (k *Kubernetes) Get(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+
+	dr, err := k.resourceClient(obj.GroupVersionKind().GroupKind(), obj.GetNamespace())
+	if err != nil {
+		return nil, err
 	}
-	err = dr.Delete(ctx, obj.GetName(), deleteOptions)
 
-	return err
+	return dr.Get(ctx, obj.GetName(), metav1.GetOptions{})
 }
 
-// List Resources dynamically in a Kubernetes cluster using fieldselectors
+// List Resources dynamically in a Kubernetes cluster using label and field selectors (see ParseSelectors),
+// field selectors rejected by the API server are applied client-side
 func (k *Kubernetes) ListWithSelectors(ctx context.Context, objData map[string]string, selectors map[string]interface{}) (*unstructured.UnstructuredList, error) {
 
-	var labelSelector string
-	var fieldSelector string
-	var dr dynamic.ResourceInterface
-
 	apiVersion := objData["apiVersion"]
 	kind := objData["kind"]
 	namespace := objData["namespace"]
 
-	// Init discovery client and mapper
-	dc, err := discovery.NewDiscoveryClientForConfig(k.Config)
+	// Use empty group name if root apiversion
+	group := strings.Split(apiVersion, "/")[0]
+	if group == apiVersion {
+		group = ""
+	}
+
+	emptyList := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{},
+	}
+
+	dr, err := k.resourceClient(schema.GroupKind{Kind: kind, Group: group}, namespace)
 	if err != nil {
-		logrus.Debugln(err)
-		return nil, err
+		return emptyList, err
 	}
 
-	// Get GVR
-	groupResources, err := restmapper.GetAPIGroupResources(dc)
+	labelSelector, fieldSelector, err := ParseSelectors(selectors)
 	if err != nil {
-		logrus.Debugln(err)
-		return nil, err
+		return emptyList, err
 	}
 
-	// Use empty group name if root apiversion
-	group := strings.Split(apiVersion, "/")[0]
-	if group == apiVersion {
-		group = ""
+	logrus.Debugf("Using selectors: %v && %v", labelSelector, fieldSelector)
+
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+
+	retrievedObjects, err := dr.List(ctx, metav1.ListOptions{
+		FieldSelector: fieldSelector.String(),
+		LabelSelector: labelSelector.String(),
+	})
+
+	// Most fields (e.g. status.phase on custom resources) can't be selected server-side
+	if apierrors.IsBadRequest(err) && !fieldSelector.Empty() {
+		logrus.Debugf("Field selector %v rejected by the API server, filtering client-side: %v", fieldSelector, err)
+		retrievedObjects, err = dr.List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector.String(),
+		})
+		if err == nil {
+			retrievedObjects.Items, err = filterByFields(retrievedObjects.Items, fieldSelector)
+		}
 	}
 
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
-	mapping, err := mapper.RESTMapping(schema.GroupKind{Kind: kind, Group: group})
 	if err != nil {
 		logrus.Debugln(err)
-		return nil, err
+		return emptyList, err
 	}
 
-	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace == "" {
-		namespace = defaultNamespace
-	}
+	logrus.Debugf("Number of objects retrieved %d", len(retrievedObjects.Items))
 
-	// Init dynamic client
-	dr = k.DynClient.Resource(mapping.Resource)
-	if namespace != "" {
-		dr = k.DynClient.Resource(mapping.Resource).Namespace(namespace)
+	return retrievedObjects, nil
+}
+
+// Reset the cached discovery information, e.g. after CRDs have been created
+func (k *Kubernetes) ResetMapper() {
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.mapper != nil {
+		k.mapper.Reset()
 	}
+}
+
+// Return the REST mapper, discovery information is cached until ResetMapper is called
+func (k *Kubernetes) restMapper() (*restmapper.DeferredDiscoveryRESTMapper, error) {
 
-	// Composing selectors
-	for k, v := range selectors {
-		if strings.HasPrefix(k, "metadata.labels") {
-			labelSelector = fmt.Sprintf("%v=%v,%s", strings.ReplaceAll(k, "metadata.labels.", ""), v, labelSelector)
-		} else {
-			fieldSelector = fmt.Sprintf("%v=%v,%s", k, v, fieldSelector)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.mapper == nil {
+		dc, err := discovery.NewDiscoveryClientForConfig(k.Config)
+		if err != nil {
+			logrus.Debugln(err)
+			return nil, err
 		}
+		k.mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
 	}
+	return k.mapper, nil
+}
 
-	fieldSelector = strings.TrimSuffix(fieldSelector, ",")
-	labelSelector = strings.TrimSuffix(labelSelector, ",")
+// Return the REST mapping of a kind, the discovery information is refreshed
+// once if the kind is unknown (e.g. its CRD has been created meanwhile)
+func (k *Kubernetes) restMapping(gk schema.GroupKind) (*meta.RESTMapping, error) {
 
-	logrus.Debugf("Using selectors: %v && %v", labelSelector, fieldSelector)
+	mapper, err := k.restMapper()
+	if err != nil {
+		return nil, err
+	}
 
-	retrievedObjects, _ := dr.List(ctx, metav1.ListOptions{
-		FieldSelector: strings.TrimSuffix(fieldSelector, ","),
-		LabelSelector: strings.TrimSuffix(labelSelector, ","),
-	})
+	mapping, err := mapper.RESTMapping(gk)
+	if meta.IsNoMatchError(err) {
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gk)
+	}
+	if err != nil {
+		logrus.Debugln(err)
+		return nil, err
+	}
+	return mapping, nil
+}
 
-	if retrievedObjects == nil {
-		retrievedObjects = &unstructured.UnstructuredList{
-			Items: []unstructured.Unstructured{},
-		}
+// Return the dynamic client of a kind, namespaced resources default to the "default" namespace
+func (k *Kubernetes) resourceClient(gk schema.GroupKind, namespace string) (dynamic.ResourceInterface, error) {
+
+	mapping, err := k.restMapping(gk)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil || retrievedObjects == nil {
-		logrus.Debugln(err)
-		return retrievedObjects, err
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return k.DynClient.Resource(mapping.Resource), nil
 	}
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return k.DynClient.Resource(mapping.Resource).Namespace(namespace), nil
+}
 
-	logrus.Debugf("Number of objects retrieved %d", len(retrievedObjects.Items))
+// Return an ObjectError for the given object, API status details
+// (reason and code) are only set if the error comes from the API server
+func NewObjectError(obj *unstructured.Unstructured, err error) ObjectError {
 
-	return retrievedObjects, nil
+	objErr := ObjectError{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Message:    err.Error(),
+	}
+
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		objErr.Reason = string(status.Status().Reason)
+		objErr.Code = int(status.Status().Code)
+	}
+
+	return objErr
+}
+
+func (e ObjectError) Error() string {
+	return fmt.Sprintf("%s:%s:%s:%s: %s", e.APIVersion, e.Kind, e.Namespace, e.Name, e.Message)
 }
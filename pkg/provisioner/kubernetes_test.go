@@ -0,0 +1,73 @@
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Return a provisioner backed by fake clients serving pods
+func newFakeProvisioner() (*Kubernetes, *fakedynamic.FakeDynamicClient) {
+
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+	}
+
+	dynClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Version: "v1", Resource: "pods"}: "PodList"},
+	)
+
+	return &Kubernetes{
+		DynClient: dynClient,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discovery)),
+	}, dynClient
+}
+
+func TestListWithSelectors(t *testing.T) {
+
+	prv, _ := newFakeProvisioner()
+
+	objects, err := prv.ListWithSelectors(context.TODO(), map[string]string{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"namespace":  "default",
+	}, map[string]interface{}{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(objects.Items))
+}
+
+func TestListWithSelectorsErrors(t *testing.T) {
+
+	prv, dynClient := newFakeProvisioner()
+	dynClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("the server is currently unable to handle the request")
+	})
+
+	// A failed list must never look like an empty one (e.g. for absent assertions)
+	objects, err := prv.ListWithSelectors(context.TODO(), map[string]string{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"namespace":  "default",
+	}, map[string]interface{}{})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(objects.Items))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func (_m *ProvisionerMock) CreateOrUpdate(ctx context.Context, object *unstructured.Unstructured) error {
@@ -16,6 +17,35 @@ func (_m *ProvisionerMock) Delete(ctx context.Context, object *unstructured.Unst
 	return args.Error(0)
 }
 
+func (_m *ProvisionerMock) DryRun(ctx context.Context, object *unstructured.Unstructured) error {
+	args := _m.Called(ctx, object)
+	return args.Error(0)
+}
+
+func (_m *ProvisionerMock) Get(ctx context.Context, object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	args := _m.Called(ctx, object)
+	return args.Get(0).(*unstructured.Unstructured), args.Error(1)
+}
+
+func (_m *ProvisionerMock) Patch(ctx context.Context, object *unstructured.Unstructured, patchType types.PatchType, data []byte) error {
+	args := _m.Called(ctx, object, patchType, data)
+	return args.Error(0)
+}
+
+func (_m *ProvisionerMock) Evict(ctx context.Context, pod *unstructured.Unstructured) error {
+	args := _m.Called(ctx, pod)
+	return args.Error(0)
+}
+
+func (_m *ProvisionerMock) ResetMapper() {
+	_m.Called()
+}
+
+func (_m *ProvisionerMock) Logs(ctx context.Context, namespace, name, container string, tailLines int64) (string, error) {
+	args := _m.Called(ctx, namespace, name, container, tailLines)
+	return args.String(0), args.Error(1)
+}
+
 func (_m *ProvisionerMock) ListWithSelectors(
 	ctx context.Context,
 	objData map[string]string,
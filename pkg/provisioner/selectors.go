@@ -0,0 +1,165 @@
+package provisioner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const labelsPrefix = "metadata.labels."
+
+// Keys of the selectors map that aren't field paths
+const (
+	labelSelectorKey    = "labelSelector"
+	fieldSelectorKey    = "fieldSelector"
+	matchLabelsKey      = "matchLabels"
+	matchExpressionsKey = "matchExpressions"
+)
+
+// ParseSelectors returns the label and field selectors of a selectors map, every selector has to match.
+// Keys are label equalities (metadata.labels.<key>), field equalities (any other field path),
+// selector strings (labelSelector, fieldSelector) or matchLabels/matchExpressions as in a LabelSelector
+func ParseSelectors(selectors map[string]interface{}) (labels.Selector, fields.Selector, error) {
+
+	labelSelector := labels.NewSelector()
+	fieldSelectors := []fields.Selector{}
+
+	if selectors[matchLabelsKey] != nil || selectors[matchExpressionsKey] != nil {
+		requirements, err := labelSelectorRequirements(selectors)
+		if err != nil {
+			return nil, nil, err
+		}
+		labelSelector = labelSelector.Add(requirements...)
+	}
+
+	// Sorted, so that the same selectors always produce the same query
+	var keys []string
+	for key := range selectors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := selectors[key]
+
+		switch {
+		case key == labelSelectorKey:
+			parsed, err := labels.Parse(fmt.Sprint(value))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %v", key, err)
+			}
+			requirements, _ := parsed.Requirements()
+			labelSelector = labelSelector.Add(requirements...)
+
+		case key == fieldSelectorKey:
+			parsed, err := fields.ParseSelector(fmt.Sprint(value))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %v", key, err)
+			}
+			fieldSelectors = append(fieldSelectors, parsed)
+
+		case key == matchLabelsKey || key == matchExpressionsKey:
+			continue
+
+		case strings.HasPrefix(key, labelsPrefix):
+			requirement, err := labels.NewRequirement(strings.TrimPrefix(key, labelsPrefix), selection.Equals, []string{fmt.Sprint(value)})
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid label selector %s: %v", key, err)
+			}
+			labelSelector = labelSelector.Add(*requirement)
+
+		default:
+			fieldSelectors = append(fieldSelectors, fields.OneTermEqualSelector(key, fmt.Sprint(value)))
+		}
+	}
+
+	return labelSelector, fields.AndSelectors(fieldSelectors...), nil
+}
+
+// Convert matchLabels and matchExpressions into label requirements
+func labelSelectorRequirements(selectors map[string]interface{}) (labels.Requirements, error) {
+
+	data, err := json.Marshal(map[string]interface{}{
+		matchLabelsKey:      selectors[matchLabelsKey],
+		matchExpressionsKey: selectors[matchExpressionsKey],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	labelSelector := &metav1.LabelSelector{}
+	err = json.Unmarshal(data, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s/%s: %v", matchLabelsKey, matchExpressionsKey, err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s/%s: %v", matchLabelsKey, matchExpressionsKey, err)
+	}
+
+	requirements, _ := selector.Requirements()
+	return requirements, nil
+}
+
+// Filter objects by field selector client-side, for the fields the API server
+// can't select on. Fields are JSONPath expressions, missing fields are empty
+func filterByFields(objects []unstructured.Unstructured, selector fields.Selector) ([]unstructured.Unstructured, error) {
+
+	filtered := []unstructured.Unstructured{}
+	for _, obj := range objects {
+		matched := true
+		for _, requirement := range selector.Requirements() {
+			value, err := fieldValue(obj.Object, requirement.Field)
+			if err != nil {
+				return nil, err
+			}
+
+			switch requirement.Operator {
+			case selection.Equals, selection.DoubleEquals:
+				matched = value == requirement.Value
+			case selection.NotEquals:
+				matched = value != requirement.Value
+			default:
+				return nil, fmt.Errorf("unsupported field selector operator '%s'", requirement.Operator)
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, obj)
+		}
+	}
+
+	return filtered, nil
+}
+
+// Return the value of a field of an object as a string, e.g. status.phase or spec.containers[0].image
+func fieldValue(obj map[string]interface{}, field string) (string, error) {
+
+	path := jsonpath.New(field)
+	path.AllowMissingKeys(true)
+	err := path.Parse(fmt.Sprintf("{.%s}", field))
+	if err != nil {
+		return "", fmt.Errorf("invalid field %s: %v", field, err)
+	}
+
+	results, err := path.FindResults(obj)
+	if err != nil {
+		return "", fmt.Errorf("invalid field %s: %v", field, err)
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprint(results[0][0].Interface()), nil
+}
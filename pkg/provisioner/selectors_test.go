@@ -0,0 +1,83 @@
+package provisioner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+func TestParseSelectors(t *testing.T) {
+
+	labelSelector, fieldSelector, err := ParseSelectors(map[string]interface{}{
+		"metadata.labels.app": "echo",
+		"metadata.name":       "echo-1",
+		"labelSelector":       "tier in (web,api),!canary",
+		"fieldSelector":       "status.phase!=Failed",
+		"matchExpressions": []interface{}{
+			map[string]interface{}{"key": "env", "operator": "NotIn", "values": []interface{}{"dev"}},
+		},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "app=echo,!canary,env notin (dev),tier in (api,web)", labelSelector.String())
+	assert.Equal(t, "status.phase!=Failed,metadata.name=echo-1", fieldSelector.String())
+
+	// Values are escaped
+	_, fieldSelector, err = ParseSelectors(map[string]interface{}{"metadata.name": "a,b"})
+	assert.Nil(t, err)
+	assert.Equal(t, `metadata.name=a\,b`, fieldSelector.String())
+
+	// Empty selectors match everything
+	labelSelector, fieldSelector, err = ParseSelectors(nil)
+	assert.Nil(t, err)
+	assert.True(t, labelSelector.Empty())
+	assert.True(t, fieldSelector.Empty())
+}
+
+func TestParseSelectorsErrors(t *testing.T) {
+
+	for _, selectors := range []map[string]interface{}{
+		{"labelSelector": "app in ("},
+		{"fieldSelector": "status.phase"},
+		{"metadata.labels.app": "not a valid value"},
+		{"matchExpressions": []interface{}{map[string]interface{}{"key": "env", "operator": "Near"}}},
+		{"matchLabels": "app=echo"},
+	} {
+		_, _, err := ParseSelectors(selectors)
+		assert.NotNil(t, err, selectors)
+	}
+}
+
+func TestFilterByFields(t *testing.T) {
+
+	newPod := func(name, phase, image string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"image": image}},
+			},
+			"status": map[string]interface{}{"phase": phase},
+		}}
+	}
+	pods := []unstructured.Unstructured{
+		newPod("web-1", "Running", "nginx:1.21"),
+		newPod("web-2", "Pending", "nginx:1.21"),
+		newPod("web-3", "Running", "nginx:1.20"),
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "no-status"}}},
+	}
+
+	selector, _ := fields.ParseSelector("status.phase=Running,spec.containers[0].image!=nginx:1.20")
+	filtered, err := filterByFields(pods, selector)
+	assert.Nil(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "web-1", filtered[0].GetName())
+
+	// Missing fields are empty
+	selector, _ = fields.ParseSelector("status.phase=")
+	filtered, err = filterByFields(pods, selector)
+	assert.Nil(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "no-status", filtered[0].GetName())
+}
@@ -2,26 +2,51 @@ package provisioner
 
 import (
 	"context"
+	"sync"
 
 	"github.com/stretchr/testify/mock"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 )
 
 // Interfaces
 type Provisioner interface {
 	CreateOrUpdate(context.Context, *unstructured.Unstructured) error
 	Delete(context.Context, *unstructured.Unstructured) error
+	DryRun(context.Context, *unstructured.Unstructured) error
+	Get(context.Context, *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	Patch(context.Context, *unstructured.Unstructured, types.PatchType, []byte) error
+	Evict(context.Context, *unstructured.Unstructured) error
+	ResetMapper()
+	Logs(ctx context.Context, namespace, name, container string, tailLines int64) (string, error)
 	ListWithSelectors(context.Context, map[string]string, map[string]interface{}) (*unstructured.UnstructuredList, error)
 }
 
+// Data
+
+// ObjectError is an error returned by the API for a given object
+type ObjectError struct {
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string `yaml:"kind" json:"kind"`
+	Namespace  string `yaml:"namespace" json:"namespace"`
+	Name       string `yaml:"name" json:"name"`
+	Reason     string `yaml:"reason" json:"reason"`
+	Code       int    `yaml:"code" json:"code"`
+	Message    string `yaml:"message" json:"message"`
+}
+
 // Provisioners
 type Kubernetes struct {
 	Client    *kubernetes.Clientset
 	DynClient dynamic.Interface
 	Config    *rest.Config
+
+	mu     sync.Mutex
+	mapper *restmapper.DeferredDiscoveryRESTMapper
 }
 
 type ProvisionerMock struct {
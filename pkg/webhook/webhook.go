@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+
+	v1 "github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1"
+	"github.com/ish-xyz/go-kubetest/pkg/apis/gokubetest/v1beta1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Path of the conversion endpoint, referenced by the CRDs
+const ConvertPath = "/convert"
+
+func NewServer(address, certFile, keyFile string) *Server {
+	return &Server{
+		Address:  address,
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}
+}
+
+// Run serves the webhook over TLS, the API server doesn't call plain HTTP webhooks
+func (s *Server) Run() error {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ConvertPath, Convert)
+
+	logrus.Infof("Conversion webhook listening on %s", s.Address)
+	return http.ListenAndServeTLS(s.Address, s.CertFile, s.KeyFile, mux)
+}
+
+// Convert handles a ConversionReview, a failure on any object fails the whole review.
+// The API server POSTs the reviews as JSON, other requests are rejected before decoding
+func Convert(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		http.Error(w, fmt.Sprintf("unsupported content type %q", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	review := &ConversionReview{}
+	err = json.NewDecoder(r.Body).Decode(review)
+	if err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("invalid ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := &ConversionResponse{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, obj := range review.Request.Objects {
+		converted, err := convertObject(obj.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			logrus.Warningf("Conversion to %s failed: %v", review.Request.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			break
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	review.Request = nil
+	review.Response = response
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// Convert an object to the desired version through the storage version (v1)
+func convertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+
+	meta := &metav1.PartialObjectMetadata{}
+	err := json.Unmarshal(raw, meta)
+	if err != nil {
+		return nil, err
+	}
+	if meta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+	if meta.Kind != "TestDefinition" {
+		return nil, fmt.Errorf("kind %s has no conversion", meta.Kind)
+	}
+
+	hub := &v1.TestDefinition{}
+	switch meta.APIVersion {
+	case v1.SchemeGroupVersion.String():
+		err = json.Unmarshal(raw, hub)
+	case v1beta1.SchemeGroupVersion.String():
+		obj := &v1beta1.TestDefinition{}
+		if err = json.Unmarshal(raw, obj); err == nil {
+			err = obj.ConvertTo(hub)
+		}
+	default:
+		err = fmt.Errorf("unknown version %s", meta.APIVersion)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s %s/%s: %v", meta.Kind, meta.Namespace, meta.Name, err)
+	}
+
+	var converted runtime.Object
+	switch desiredAPIVersion {
+	case v1.SchemeGroupVersion.String():
+		converted = hub
+	case v1beta1.SchemeGroupVersion.String():
+		obj := &v1beta1.TestDefinition{}
+		err = obj.ConvertFrom(hub)
+		converted = obj
+	default:
+		err = fmt.Errorf("unknown version %s", desiredAPIVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(converted)
+}
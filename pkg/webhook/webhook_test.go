@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func runReview(t *testing.T, desiredAPIVersion string, objects ...string) *ConversionResponse {
+
+	review := &ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &ConversionRequest{
+			UID:               "review-1",
+			DesiredAPIVersion: desiredAPIVersion,
+		},
+	}
+	for _, obj := range objects {
+		review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: []byte(obj)})
+	}
+	body, _ := json.Marshal(review)
+
+	request := httptest.NewRequest(http.MethodPost, ConvertPath, bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	Convert(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	response := &ConversionReview{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), response))
+	assert.Equal(t, "review-1", string(response.Response.UID))
+	return response.Response
+}
+
+func TestConvertToV1(t *testing.T) {
+
+	response := runReview(t, "go-kubetest.io/v1", `{
+		"apiVersion": "go-kubetest.io/v1beta1",
+		"kind": "TestDefinition",
+		"metadata": {"name": "webhook", "namespace": "default"},
+		"spec": {
+			"assert": [{
+				"name": "limits",
+				"type": "expectedExpression",
+				"expectedExpression": {"resource": "v1:Pod:default", "expression": "has(object.spec)"}
+			}]
+		}
+	}`)
+
+	assert.Equal(t, metav1.StatusSuccess, response.Result.Status)
+	if assert.Len(t, response.ConvertedObjects, 1) {
+		obj := map[string]interface{}{}
+		json.Unmarshal(response.ConvertedObjects[0].Raw, &obj)
+		spec := obj["spec"].(map[string]interface{})
+
+		assert.Equal(t, "go-kubetest.io/v1", obj["apiVersion"])
+		assert.Equal(t, []interface{}{}, spec["resources"])
+		assert.Equal(t, map[string]interface{}{}, spec["setup"])
+		assert.Equal(t, "has(object.spec)", spec["assert"].([]interface{})[0].(map[string]interface{})["expression"])
+	}
+}
+
+func TestConvertToV1beta1(t *testing.T) {
+
+	response := runReview(t, "go-kubetest.io/v1beta1", `{
+		"apiVersion": "go-kubetest.io/v1",
+		"kind": "TestDefinition",
+		"metadata": {"name": "webhook", "namespace": "default"},
+		"spec": {
+			"resources": ["manifests"],
+			"setup": {},
+			"teardown": {},
+			"assert": [{"name": "state", "type": "expectedState", "testResource": "manifests"}]
+		}
+	}`)
+
+	assert.Equal(t, metav1.StatusSuccess, response.Result.Status)
+	if assert.Len(t, response.ConvertedObjects, 1) {
+		obj := map[string]interface{}{}
+		json.Unmarshal(response.ConvertedObjects[0].Raw, &obj)
+		spec := obj["spec"].(map[string]interface{})
+
+		assert.Equal(t, "go-kubetest.io/v1beta1", obj["apiVersion"])
+		assert.Nil(t, spec["setup"])
+		assert.Equal(t, map[string]interface{}{"testResource": "manifests"}, spec["assert"].([]interface{})[0].(map[string]interface{})["expectedState"])
+	}
+}
+
+func TestConvertFailure(t *testing.T) {
+
+	response := runReview(t, "go-kubetest.io/v1", `{
+		"apiVersion": "go-kubetest.io/v1beta1",
+		"kind": "TestDefinition",
+		"metadata": {"name": "webhook", "namespace": "default"},
+		"spec": {"assert": [{"name": "state", "type": "expectedState"}]}
+	}`)
+
+	assert.Equal(t, metav1.StatusFailure, response.Result.Status)
+	assert.Contains(t, response.Result.Message, "TestDefinition default/webhook")
+	assert.Empty(t, response.ConvertedObjects)
+}
+
+func TestConvertSameVersion(t *testing.T) {
+
+	obj := `{"apiVersion":"go-kubetest.io/v1","kind":"TestResult","metadata":{"name":"test-1"}}`
+	response := runReview(t, "go-kubetest.io/v1", obj)
+
+	assert.Equal(t, metav1.StatusSuccess, response.Result.Status)
+	assert.JSONEq(t, obj, string(response.ConvertedObjects[0].Raw))
+}
+
+func TestConvertInvalidRequest(t *testing.T) {
+
+	tests := map[string]struct {
+		method      string
+		contentType string
+		code        int
+	}{
+		"get":          {http.MethodGet, "application/json", http.StatusMethodNotAllowed},
+		"yaml":         {http.MethodPost, "application/yaml", http.StatusUnsupportedMediaType},
+		"no type":      {http.MethodPost, "", http.StatusUnsupportedMediaType},
+		"charset":      {http.MethodPost, "application/json; charset=utf-8", http.StatusBadRequest},
+		"not a review": {http.MethodPost, "application/json", http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		request := httptest.NewRequest(test.method, ConvertPath, bytes.NewReader([]byte(`{}`)))
+		if test.contentType != "" {
+			request.Header.Set("Content-Type", test.contentType)
+		}
+		recorder := httptest.NewRecorder()
+		Convert(recorder, request)
+
+		assert.Equal(t, test.code, recorder.Code, name)
+	}
+}